@@ -0,0 +1,132 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Format key: dgk_<prefix>_<secret>. Prefix terlihat di daftar key dan log sehingga
+// key bisa dikenali tanpa menyimpan secret-nya.
+const (
+	keyPrefix = "dgk_"
+	// Last used hanya ditulis ulang jika lebih lama dari ini, supaya tidak update setiap request
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrInvalidKey   = errors.New("invalid api key")
+	ErrInvalidScope = errors.New("invalid scope")
+)
+
+// Generate membuat key baru dan mengembalikan key lengkap, prefix dan hash-nya
+func Generate() (key, prefix, hash string, err error) {
+	prefixBytes := make([]byte, 6)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = keyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+	return key, prefix, Hash(key), nil
+}
+
+// Hash menghitung sha256 dari key; key sudah acak penuh sehingga tidak perlu bcrypt
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// prefixOf mengambil prefix dari key lengkap
+func prefixOf(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, keyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// NormalizeScopes memvalidasi scope dan mengembalikannya dalam bentuk terurut dipisah koma
+func NormalizeScopes(scopes []string) (string, error) {
+	seen := map[string]bool{}
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		valid := false
+		for _, known := range model.Scopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return "", ErrInvalidScope
+	}
+	sort.Strings(result)
+	return strings.Join(result, ","), nil
+}
+
+// Authenticate mencari key berdasarkan prefix lalu membandingkan hash. Key yang
+// dicabut, kadaluarsa, atau milik principal yang dinonaktifkan ditolak.
+func Authenticate(db *gorm.DB, key, ip string) (*model.APIKey, error) {
+	prefix, ok := prefixOf(key)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	var record model.APIKey
+	if err := db.Where("prefix = ? AND revoked_at IS NULL", prefix).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(record.KeyHash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	if record.ExpiresAt != nil && record.ExpiresAt.Before(now) {
+		return nil, ErrInvalidKey
+	}
+
+	var principal model.ServicePrincipal
+	if err := db.Select("disabled_at").
+		Where("service_principal_id = ?", record.ServicePrincipalID).
+		First(&principal).Error; err != nil || principal.DisabledAt != nil {
+		return nil, ErrInvalidKey
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) > lastUsedResolution {
+		db.Model(&model.APIKey{}).Where("api_key_id = ?", record.APIKeyID).
+			Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+		record.LastUsedAt = &now
+		record.LastUsedIP = &ip
+	}
+	return &record, nil
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"task-golang-batch2/password"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Config adalah seluruh konfigurasi aplikasi. Tag config adalah nama key di file
+// YAML/TOML dan nama flag (section.key), tag env adalah variabel environment, dan
+// secret:"true" menandai nilai yang disamarkan oleh --print-config.
+type Config struct {
+	Server   Server   `config:"server"`
+	Database Database `config:"database"`
+	CORS     CORS     `config:"cors"`
+	JWT      JWT      `config:"jwt"`
+	Tokens   Tokens   `config:"tokens"`
+	Password Password `config:"password"`
+	Limits   Limits   `config:"limits"`
+	Features Features `config:"features"`
+	Workers  Workers  `config:"workers"`
+	Notify   Notify   `config:"notify"`
+	Log      Log      `config:"log"`
+}
+
+type Server struct {
+	Port              int           `config:"port" env:"PORT"`
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `config:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `config:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `config:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	// Batas waktu menunggu request yang sedang berjalan dan worker selesai saat SIGTERM
+	ShutdownTimeout time.Duration `config:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// IP atau CIDR reverse proxy yang header X-Forwarded-For-nya dipercaya untuk
+	// menentukan IP klien (throttle login, audit). Kosong berarti tidak ada proxy
+	// yang dipercaya dan IP klien diambil dari koneksi langsung.
+	TrustedProxies []string `config:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"` // Dipisah koma di env dan flag
+}
+
+type Database struct {
+	DSN             string        `config:"dsn" env:"DATABASE" secret:"true"`
+	MaxOpenConns    int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	// Jalankan migrasi yang belum dijalankan saat startup; jika false, startup gagal
+	// selama skema belum mutakhir
+	AutoMigrate bool `config:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+type CORS struct {
+	AllowedOrigins []string `config:"allowed_origins" env:"CORS_ORIGINS"` // Dipisah koma di env dan flag
+}
+
+// JWT lihat NewKeyset di main.go untuk format JWT_KEYS dan alur rotasi
+type JWT struct {
+	Issuer       string `config:"issuer" env:"JWT_ISSUER"`
+	Audience     string `config:"audience" env:"JWT_AUDIENCE"`
+	SigningKey   string `config:"signing_key" env:"SIGNING_KEY" secret:"true"`
+	SigningKeyID string `config:"signing_key_id" env:"SIGNING_KEY_ID"`
+	Keys         string `config:"keys" env:"JWT_KEYS"` // Berisi path file kunci, bukan kuncinya
+	ActiveKID    string `config:"active_kid" env:"JWT_ACTIVE_KID"`
+}
+
+type Tokens struct {
+	AccessTTL        time.Duration `config:"access_ttl" env:"ACCESS_TOKEN_TTL"`
+	RefreshTTL       time.Duration `config:"refresh_ttl" env:"REFRESH_TOKEN_TTL"`
+	ChallengeTTL     time.Duration `config:"challenge_ttl" env:"TWO_FACTOR_CHALLENGE_TTL"`
+	PasswordResetTTL time.Duration `config:"password_reset_ttl" env:"PASSWORD_RESET_TTL"`
+}
+
+type Password struct {
+	MinLength  int    `config:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	Classes    string `config:"classes" env:"PASSWORD_CLASSES"` // Misal "upper,lower,digit,symbol"
+	Hasher     string `config:"hasher" env:"PASSWORD_HASHER"`   // argon2id atau bcrypt
+	BcryptCost int    `config:"bcrypt_cost" env:"BCRYPT_COST"`
+}
+
+type Limits struct {
+	// Transfer di atas nilai ini (minor unit) wajib verifikasi 2FA, 0 berarti tidak aktif
+	StepUpAmount int64 `config:"step_up_amount" env:"STEP_UP_AMOUNT"`
+	// Jumlah login gagal sebelum username / IP dikunci
+	LoginMaxFailures   int `config:"login_max_failures" env:"LOGIN_MAX_FAILURES"`
+	LoginIPMaxFailures int `config:"login_ip_max_failures" env:"LOGIN_IP_MAX_FAILURES"`
+}
+
+type Features struct {
+	Registration bool `config:"registration" env:"FEATURE_REGISTRATION"`
+	Scheduler    bool `config:"scheduler" env:"FEATURE_SCHEDULER"`
+	Reconcile    bool `config:"reconcile" env:"FEATURE_RECONCILE"`
+	// Rekonsiliasi membekukan akun yang saldonya selisih
+	ReconcileAutoFreeze bool `config:"reconcile_auto_freeze" env:"RECONCILE_AUTO_FREEZE"`
+}
+
+type Workers struct {
+	SchedulerInterval time.Duration `config:"scheduler_interval" env:"SCHEDULER_INTERVAL"`
+	ReconcileInterval time.Duration `config:"reconcile_interval" env:"RECONCILE_INTERVAL"`
+}
+
+// Notify belum punya kanal produksi; salah satu harus dipilih secara eksplisit
+// supaya token reset password tidak tercetak ke stdout tanpa disadari
+type Notify struct {
+	File   string `config:"file" env:"NOTIFY_FILE"`
+	Stdout bool   `config:"stdout" env:"NOTIFY_STDOUT"` // Hanya untuk development
+}
+
+type Log struct {
+	Level  string `config:"level" env:"LOG_LEVEL"`   // debug, info, warn atau error
+	Format string `config:"format" env:"LOG_FORMAT"` // json atau text
+	// Query SQL yang lebih lama dari ini dicatat sebagai warning, 0 berarti tidak aktif.
+	// Semua query baru dicatat pada level debug.
+	SlowQuery time.Duration `config:"slow_query" env:"LOG_SLOW_QUERY"`
+}
+
+// Default adalah nilai yang dipakai jika tidak diatur di file, env maupun flag
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:              8080,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Database: Database{
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"http://localhost:5173", "http://localhost:3000"},
+		},
+		JWT: JWT{
+			Issuer:       "task-golang-batch2",
+			Audience:     "task-golang-batch2",
+			SigningKeyID: "default",
+		},
+		Tokens: Tokens{
+			AccessTTL:        15 * time.Minute,
+			RefreshTTL:       30 * 24 * time.Hour,
+			ChallengeTTL:     5 * time.Minute,
+			PasswordResetTTL: 30 * time.Minute,
+		},
+		Password: Password{
+			MinLength:  password.DefaultPolicy.MinLength,
+			Classes:    "upper,lower,digit",
+			Hasher:     "argon2id",
+			BcryptCost: bcrypt.DefaultCost,
+		},
+		Limits: Limits{
+			LoginMaxFailures:   5,
+			LoginIPMaxFailures: 50,
+		},
+		Features: Features{
+			Registration: true,
+			Scheduler:    true,
+			Reconcile:    true,
+		},
+		Workers: Workers{
+			SchedulerInterval: time.Minute,
+			ReconcileInterval: 24 * time.Hour,
+		},
+		Log: Log{
+			Level:     "info",
+			Format:    "json",
+			SlowQuery: 200 * time.Millisecond,
+		},
+	}
+}
+
+// Validate untuk perintah yang hanya butuh database, misal migrate
+func (d Database) Validate() error {
+	var errs []error
+	check := func(ok bool, message string) {
+		if !ok {
+			errs = append(errs, errors.New(message))
+		}
+	}
+
+	check(d.DSN != "", "database.dsn (DATABASE) is required")
+	check(d.MaxOpenConns >= 0, "database.max_open_conns must not be negative")
+	check(d.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	check(d.MaxOpenConns == 0 || d.MaxIdleConns <= d.MaxOpenConns,
+		"database.max_idle_conns must not exceed database.max_open_conns")
+	check(d.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	check(d.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative")
+	return errors.Join(errs...)
+}
+
+// Validate untuk logger, dipakai juga oleh perintah migrate
+func (l Log) Validate() error {
+	var errs []error
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		errs = append(errs, errors.New("log.level must be debug, info, warn or error"))
+	}
+	if l.Format != "json" && l.Format != "text" {
+		errs = append(errs, errors.New("log.format must be json or text"))
+	}
+	if l.SlowQuery < 0 {
+		errs = append(errs, errors.New("log.slow_query must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate mengembalikan semua kesalahan konfigurasi sekaligus
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port must be between 1 and 65535")
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout must not be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	check(len(c.CORS.AllowedOrigins) > 0, "cors.allowed_origins must not be empty")
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		check(err == nil && parsed.Scheme != "" && parsed.Host != "" && parsed.Path == "",
+			"cors.allowed_origins: %q must be scheme://host[:port]", origin)
+	}
+
+	check(c.JWT.Issuer != "", "jwt.issuer must not be empty")
+	check(c.JWT.Audience != "", "jwt.audience must not be empty")
+	check(c.JWT.SigningKey != "" || c.JWT.ActiveKID != "", "jwt.signing_key (SIGNING_KEY) or jwt.active_kid (JWT_ACTIVE_KID) is required")
+	check(c.JWT.SigningKey == "" || c.JWT.SigningKeyID != "", "jwt.signing_key_id must not be empty")
+
+	check(c.Tokens.AccessTTL > 0, "tokens.access_ttl must be positive")
+	check(c.Tokens.RefreshTTL > c.Tokens.AccessTTL, "tokens.refresh_ttl must be longer than tokens.access_ttl")
+	check(c.Tokens.ChallengeTTL > 0, "tokens.challenge_ttl must be positive")
+	check(c.Tokens.PasswordResetTTL > 0, "tokens.password_reset_ttl must be positive")
+
+	policy := password.DefaultPolicy
+	check(c.Password.MinLength > 0 && c.Password.MinLength <= policy.MaxLength,
+		"password.min_length must be between 1 and %d", policy.MaxLength)
+	if err := policy.ParseClasses(c.Password.Classes); err != nil {
+		errs = append(errs, fmt.Errorf("password.classes: %w", err))
+	}
+	check(c.Password.Hasher == "argon2id" || c.Password.Hasher == "bcrypt", "password.hasher must be argon2id or bcrypt")
+	check(c.Password.BcryptCost >= bcrypt.MinCost && c.Password.BcryptCost <= bcrypt.MaxCost,
+		"password.bcrypt_cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+
+	check(c.Limits.StepUpAmount >= 0, "limits.step_up_amount must not be negative")
+	check(c.Limits.LoginMaxFailures > 0, "limits.login_max_failures must be positive")
+	check(c.Limits.LoginIPMaxFailures > 0, "limits.login_ip_max_failures must be positive")
+
+	check(c.Workers.SchedulerInterval > 0, "workers.scheduler_interval must be positive")
+	check(c.Workers.ReconcileInterval > 0, "workers.reconcile_interval must be positive")
+
+	check(c.Notify.File != "" || c.Notify.Stdout, "notify.file (NOTIFY_FILE) or notify.stdout (NOTIFY_STDOUT) is required")
+
+	if err := c.Log.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// redacted menggantikan nilai secret pada --print-config
+const redacted = "********"
+
+// Options adalah flag yang mengatur cara memuat konfigurasi, bukan isi konfigurasinya
+type Options struct {
+	File        string   // --config atau CONFIG_FILE
+	PrintConfig bool     // --print-config: cetak konfigurasi efektif lalu keluar
+	Args        []string // Argumen setelah flag, misal "migrate up"
+}
+
+// field adalah satu nilai konfigurasi beserta nama key, env dan penandanya
+type field struct {
+	path   string // section.key, dipakai di file dan sebagai nama flag
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// fields meratakan Config menjadi daftar field. Urutannya mengikuti deklarasi struct.
+func fields(cfg *Config) []field {
+	var result []field
+	sections := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Type().Field(i)
+		values := sections.Field(i)
+		for j := 0; j < values.NumField(); j++ {
+			key := values.Type().Field(j)
+			result = append(result, field{
+				path:   section.Tag.Get("config") + "." + key.Tag.Get("config"),
+				env:    key.Tag.Get("env"),
+				secret: key.Tag.Get("secret") == "true",
+				value:  values.Field(j),
+			})
+		}
+	}
+	return result
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set mengubah raw menjadi tipe field. Daftar dipisah koma, durasi memakai format
+// time.ParseDuration (misal 15m, 720h).
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case f.value.Type() == durationType:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s: invalid duration %q", f.path, raw)
+		}
+		f.value.SetInt(int64(parsed))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(raw)
+	case f.value.Kind() == reflect.Int || f.value.Kind() == reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", f.path, raw)
+		}
+		f.value.SetInt(parsed)
+	case f.value.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s: invalid boolean %q", f.path, raw)
+		}
+		f.value.SetBool(parsed)
+	case f.value.Kind() == reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		f.value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s: unsupported type %s", f.path, f.value.Type())
+	}
+	return nil
+}
+
+// display adalah nilai field untuk --print-config
+func (f field) display() interface{} {
+	if f.secret {
+		if f.value.IsZero() {
+			return ""
+		}
+		return redacted
+	}
+	if f.value.Type() == durationType {
+		return time.Duration(f.value.Int()).String()
+	}
+	return f.value.Interface()
+}
+
+// rawFlag menyimpan nilai flag apa adanya; baru diterapkan setelah file dan env
+type rawFlag struct {
+	value string
+}
+
+func (r *rawFlag) String() string     { return r.value }
+func (r *rawFlag) Set(s string) error { r.value = s; return nil }
+
+// Load membaca konfigurasi dengan urutan prioritas default < file < env < flag.
+// args adalah argumen program tanpa nama program (os.Args[1:]). Setiap field juga
+// tersedia sebagai flag --section.key, misal --server.port=9090. Hasilnya belum
+// divalidasi karena tiap perintah butuh bagian yang berbeda; panggil Validate.
+func Load(args []string) (Config, Options, error) {
+	cfg := Default()
+	all := fields(&cfg)
+	byPath := make(map[string]field, len(all))
+	for _, f := range all {
+		byPath[f.path] = f
+	}
+
+	var options Options
+	flags := flag.NewFlagSet("digi", flag.ContinueOnError)
+	flags.StringVar(&options.File, "config", os.Getenv("CONFIG_FILE"), "path file konfigurasi .yaml, .yml atau .toml")
+	flags.BoolVar(&options.PrintConfig, "print-config", false, "cetak konfigurasi efektif (secret disamarkan) lalu keluar")
+	raw := make(map[string]*rawFlag, len(all))
+	for _, f := range all {
+		raw[f.path] = &rawFlag{}
+		flags.Var(raw[f.path], f.path, "env "+f.env)
+	}
+	if err := flags.Parse(args); err != nil {
+		return cfg, options, err
+	}
+	options.Args = flags.Args()
+
+	if options.File != "" {
+		if err := loadFile(options.File, byPath); err != nil {
+			return cfg, options, err
+		}
+	}
+
+	for _, f := range all {
+		if value, ok := os.LookupEnv(f.env); ok {
+			if err := f.set(value); err != nil {
+				return cfg, options, fmt.Errorf("env %s: %w", f.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	flags.Visit(func(visited *flag.Flag) {
+		if f, ok := byPath[visited.Name]; ok && flagErr == nil {
+			flagErr = f.set(raw[visited.Name].value)
+		}
+	})
+	return cfg, options, flagErr
+}
+
+// loadFile membaca file YAML atau TOML dengan section yang sama seperti Config.
+// Key yang tidak dikenal ditolak supaya salah ketik tidak diam-diam diabaikan.
+func loadFile(path string, byPath map[string]field) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	document := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	default:
+		return fmt.Errorf("config file %s: extension must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	for sectionName, section := range document {
+		keys, ok := section.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("config file %s: %s must be a section", path, sectionName)
+		}
+		for key, value := range keys {
+			f, ok := byPath[sectionName+"."+key]
+			if !ok {
+				return fmt.Errorf("config file %s: unknown key %s.%s", path, sectionName, key)
+			}
+			if err := f.set(scalar(value)); err != nil {
+				return fmt.Errorf("config file %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// scalar mengubah nilai hasil decode YAML/TOML menjadi string untuk field.set
+func scalar(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// Print menulis konfigurasi efektif sebagai YAML dengan secret disamarkan
+func (c Config) Print(w io.Writer) error {
+	document := map[string]map[string]interface{}{}
+	for _, f := range fields(&c) {
+		section, key, _ := strings.Cut(f.path, ".")
+		if document[section] == nil {
+			document[section] = map[string]interface{}{}
+		}
+		document[section][key] = f.display()
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,88 @@
+package currency
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Default adalah mata uang akun yang dibuat tanpa menyebut currency
+const Default = "IDR"
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency code")
+	ErrAmountOverflow  = errors.New("converted amount is too large")
+)
+
+// minorUnits adalah jumlah digit desimal (exponent) menurut ISO 4217.
+// Semua nominal di database disimpan dalam satuan terkecil (minor unit).
+// IDR tetap 0 karena saldo lama di database tersimpan dalam rupiah utuh.
+var minorUnits = map[string]int{
+	"IDR": 0,
+	"USD": 2,
+	"SGD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"AUD": 2,
+	"MYR": 2,
+	"CNY": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"KWD": 3,
+	"BHD": 3,
+}
+
+// Normalize mengubah kode menjadi huruf besar dan memastikan kodenya dikenal.
+func Normalize(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := minorUnits[code]; !ok {
+		return "", ErrUnknownCurrency
+	}
+	return code, nil
+}
+
+// MinorUnits mengembalikan exponent ISO 4217 untuk kode mata uang.
+func MinorUnits(code string) int {
+	if exp, ok := minorUnits[code]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Format menampilkan nominal minor unit sebagai angka desimal, contoh 12345 USD -> "123.45".
+func Format(amount int64, code string) string {
+	exp := MinorUnits(code)
+	if exp == 0 {
+		return big.NewInt(amount).String()
+	}
+	return new(big.Rat).SetFrac(big.NewInt(amount), pow10(exp)).FloatString(exp)
+}
+
+// ParseRate mem-parse kurs desimal (contoh "15750.25") menjadi bilangan rasional.
+func ParseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || r.Sign() <= 0 {
+		return nil, errors.New("rate must be a positive decimal number")
+	}
+	return r, nil
+}
+
+// Convert mengonversi nominal minor unit dari mata uang `from` ke `to` dengan kurs
+// 1 from = rate to. Hasil dibulatkan ke bawah ke minor unit mata uang tujuan;
+// ErrAmountOverflow jika hasilnya tidak muat di int64.
+func Convert(amount int64, from, to string, rate *big.Rat) (int64, error) {
+	value := new(big.Rat).SetInt64(amount)
+	value.Mul(value, rate)
+	value.Mul(value, new(big.Rat).SetFrac(pow10(MinorUnits(to)), pow10(MinorUnits(from))))
+
+	converted := new(big.Int).Quo(value.Num(), value.Denom())
+	if !converted.IsInt64() {
+		return 0, ErrAmountOverflow
+	}
+	return converted.Int64(), nil
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
@@ -0,0 +1,44 @@
+package currency
+
+import (
+	"errors"
+	"math/big"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrRateNotFound = errors.New("fx rate not found")
+
+// LatestRate mengambil kurs terbaru yang sudah berlaku untuk pasangan from/to.
+// Jika hanya tersedia arah sebaliknya, kurs tersebut dibalik. Nilai kembalian kedua
+// adalah representasi desimal kurs yang disimpan sebagai snapshot di transaksi.
+func LatestRate(tx *gorm.DB, from, to string) (*big.Rat, string, error) {
+	if from == to {
+		return big.NewRat(1, 1), "1", nil
+	}
+
+	var rates []model.FxRate
+	err := tx.Where("(base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?)",
+		from, to, to, from).
+		Where("effective_at <= ?", time.Now()).
+		Order("effective_at DESC").
+		Limit(1).
+		Find(&rates).Error
+	if err != nil {
+		return nil, "", err
+	}
+	if len(rates) == 0 {
+		return nil, "", ErrRateNotFound
+	}
+
+	rate, err := ParseRate(rates[0].Rate)
+	if err != nil {
+		return nil, "", err
+	}
+	if rates[0].BaseCurrency != from {
+		rate.Inv(rate)
+	}
+	return rate, rate.FloatString(12), nil
+}
@@ -2,9 +2,15 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/ledger"
+	"task-golang-batch2/limits"
 	"task-golang-batch2/model"
+	"task-golang-batch2/service"
+	"task-golang-batch2/statement"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,15 +28,19 @@ type AccountInterface interface {
 	Transfer(*gin.Context)
 	Balance(*gin.Context)
 	Mutation(*gin.Context)
+	Statement(*gin.Context)
+	Limit(*gin.Context)
 }
 
 type accountImplement struct {
-	db *gorm.DB
+	db       *gorm.DB
+	accounts service.AccountService
 }
 
-func NewAccount(db *gorm.DB) AccountInterface {
+func NewAccount(db *gorm.DB, accounts service.AccountService) AccountInterface {
 	return &accountImplement{
-		db: db,
+		db:       db,
+		accounts: accounts,
 	}
 }
 
@@ -46,11 +56,11 @@ func (a *accountImplement) Create(c *gin.Context) {
 		return
 	}
 
-	// Create data
-	result := a.db.Create(&payload)
-	if result.Error != nil {
+	// Saldo awal diposting sebagai jurnal oleh service, bukan ditulis langsung
+	account, err := a.accounts.Create(payload)
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": result.Error.Error(),
+			"error": err.Error(),
 		})
 		return
 	}
@@ -58,7 +68,7 @@ func (a *accountImplement) Create(c *gin.Context) {
 	// Success response
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Create success",
-		"data":    payload,
+		"data":    account,
 	})
 }
 
@@ -102,29 +112,28 @@ func (a *accountImplement) Update(c *gin.Context) {
 	}
 
 	// get id from url account/update/5, 5 will be the id
-	id := c.Param("id")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "Not found",
+		})
+		return
+	}
 
-	// Find first data based on id and put to account model
-	account := model.Account{}
-	result := a.db.First(&account, "account_id = ?", id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	// Update data, perubahan saldo dicatat sebagai jurnal penyesuaian
+	if err := a.accounts.Update(id, payload.Name, payload.Tier, payload.Balance); err != nil {
+		if errors.Is(err, service.ErrAccountNotFound) {
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
 				"error": "Not found",
 			})
 			return
 		}
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": result.Error.Error(),
+			"error": err.Error(),
 		})
 		return
 	}
 
-	// Update data
-	account.Name = payload.Name
-	account.Balance = payload.Balance
-	a.db.Save(account)
-
 	// Success response
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Update success",
@@ -136,7 +145,7 @@ func (a *accountImplement) Delete(c *gin.Context) {
 	id := c.Param("id")
 
 	// Find first data based on id and delete it
-	if err := a.db.Where("account_id = ?", id).Delete(&model.Account{}).Error; err != nil {
+	if err := a.db.Where("account_id = ? AND is_system = ?", id, false).Delete(&model.Account{}).Error; err != nil {
 		// No data found and deleted
 		if err == gorm.ErrRecordNotFound {
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
@@ -163,18 +172,42 @@ func (a *accountImplement) List(c *gin.Context) {
 	// Prepare empty result
 	var accounts []model.Account
 
-	// Find and get all accounts data and put to &accounts
-	if err := a.db.Find(&accounts).Error; err != nil {
+	limit, err := pageLimit(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	cursor, err := decodeCursor(c.Query("cursor"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Find accounts data page by page ordered by account_id
+	query := a.db.Where("is_system = ?", false).Order("account_id")
+	if cursor != nil {
+		query = query.Where("account_id > ?", cursor.ID)
+	}
+	if err := query.Limit(limit + 1).Find(&accounts).Error; err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	page := pageInfo{Limit: limit}
+	if len(accounts) > limit {
+		accounts = accounts[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(pageCursor{ID: accounts[limit-1].AccountID})
+	}
+
 	// Success response
-	c.JSON(http.StatusOK, gin.H{
-		"data": accounts,
-	})
+	c.JSON(http.StatusOK, paginated(accounts, page))
 }
 
 func (a *accountImplement) My(c *gin.Context) {
@@ -224,45 +257,20 @@ func (a *accountImplement) TopUp(c *gin.Context) {
 		return
 	}
 
-	// Pastikan amount > 0
-	if amount <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be greater than zero"})
-		return
-	}
-
-	// Ambil akun berdasarkan account_id (gunakan account_id sebagai kolom)
-	var account model.Account
-	err = a.db.Where("account_id = ?", accountID).First(&account).Error
+	// Validasi akun dan posting jurnal dilakukan di service
+	_, err = a.accounts.TopUp(accountID, amount)
 	if err != nil {
-		// Jika account tidak ditemukan
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
-		return
-	}
-
-	// Mulai transaksi untuk top-up
-	err = a.db.Transaction(func(tx *gorm.DB) error {
-		// Update saldo akun dengan menambah jumlah top-up
-		if err := tx.Model(&model.Account{}).Where("account_id = ?", accountID).
-			Update("balance", gorm.Expr("balance + ?", amount)).Error; err != nil {
-			return err
-		}
-
-		// Buat entri transaksi untuk top-up
-		transaction := model.Transaction{
-			AccountID:             accountID,
-			Amount:                amount,
-			TransactionCategoryID: nil, // Bisa disesuaikan dengan kategori
-		}
-
-		if err := tx.Create(&transaction).Error; err != nil {
-			return err
+		switch {
+		case errors.Is(err, ledger.ErrInvalidAmount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be greater than zero"})
+		case errors.Is(err, service.ErrAccountNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		case errors.Is(err, ledger.ErrAccountFrozen):
+			// Akun yang dibekukan rekonsiliasi tidak bisa menerima top-up
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Top-Up Transaction Failed"})
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Top-Up Transaction Failed"})
 		return
 	}
 
@@ -272,6 +280,15 @@ func (a *accountImplement) TopUp(c *gin.Context) {
 func (a *accountImplement) Transfer(c *gin.Context) {
 	// Ambil account_id dari middleware auth (harus ada casting ke int64)
 	fromAccountIDInterface, exists := c.Get("account_id")
+	if c.GetString("role") == model.RoleService {
+		// Service principal (API key) tidak punya akun; pengirim dipilih lewat from_account_id
+		fromAccountID, err := strconv.ParseInt(c.PostForm("from_account_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from_account_id"})
+			return
+		}
+		fromAccountIDInterface, exists = fromAccountID, true
+	}
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account ID not found"})
 		return
@@ -294,48 +311,35 @@ func (a *accountImplement) Transfer(c *gin.Context) {
 		return
 	}
 
-	// Mulai transaksi transfer
-	err = a.db.Transaction(func(tx *gorm.DB) error {
-		// Ambil akun pengirim
-		var fromAccount model.Account
-		if err := tx.Where("account_id = ?", fromAccountID).First(&fromAccount).Error; err != nil {
-			return err
-		}
-
-		// Pastikan saldo mencukupi
-		if fromAccount.Balance < amount {
-			return errors.New("insufficient balance")
-		}
-
-		// Kurangi saldo dari akun pengirim
-		if err := tx.Model(&fromAccount).Update("balance", fromAccount.Balance-amount).Error; err != nil {
-			return err
-		}
+	// Kedua akun dikunci dan divalidasi di dalam transaksi milik service. API key
+	// hanya boleh mendebit akun yang ada di allow-list principal-nya.
+	if c.GetString("role") == model.RoleService {
+		_, err = a.accounts.TransferAsPrincipal(c.GetInt64("service_principal_id"), fromAccountID, toAccountID, amount)
+	} else {
+		_, err = a.accounts.Transfer(fromAccountID, toAccountID, amount)
+	}
 
-		// Tambah saldo ke akun penerima
-		var toAccount model.Account
-		if err := tx.Where("account_id = ?", toAccountID).First(&toAccount).Error; err != nil {
-			return err
-		}
-		if err := tx.Model(&toAccount).Update("balance", toAccount.Balance+amount).Error; err != nil {
-			return err
+	if err != nil {
+		// Limit terlampaui: beri tahu limit mana dan kapan kuota tersedia lagi
+		if limitErr, ok := limits.AsLimitError(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": limitErr.Error(), "limit": limitErr})
+			return
 		}
 
-		// Buat catatan transaksi
-		transaction := model.Transaction{
-			AccountID:     fromAccountID,
-			FromAccountID: &fromAccountID,
-			ToAccountID:   &toAccountID,
-			Amount:        amount,
+		switch {
+		case errors.Is(err, service.ErrSourceNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, ledger.ErrSenderNotFound), errors.Is(err, ledger.ErrRecipientNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ledger.ErrInsufficientBalance), errors.Is(err, ledger.ErrAccountFrozen),
+			errors.Is(err, ledger.ErrAmountTooSmall), errors.Is(err, currency.ErrRateNotFound),
+			errors.Is(err, currency.ErrAmountOverflow), errors.Is(err, limits.ErrCurrencyNotConfigured):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ledger.ErrSelfTransfer), errors.Is(err, ledger.ErrInvalidAmount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		if err := tx.Create(&transaction).Error; err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Transfer completed successfully"})
@@ -355,7 +359,7 @@ func (h *accountImplement) Balance(c *gin.Context) {
 	var account model.Account
 
 	// Query untuk mengambil saldo berdasarkan account_id
-	err := h.db.Select("balance").Where("account_id = ?", accountID).First(&account).Error
+	err := h.db.Select("balance", "currency").Where("account_id = ?", accountID).First(&account).Error
 	if err != nil {
 		// Jika akun tidak ditemukan, berikan error yang lebih spesifik
 		if err.Error() == "record not found" {
@@ -366,7 +370,12 @@ func (h *accountImplement) Balance(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"balance": account.Balance})
+	c.JSON(http.StatusOK, gin.H{
+		"balance":     account.Balance,
+		"currency":    account.Currency,
+		"minor_units": currency.MinorUnits(account.Currency),
+		"formatted":   currency.Format(account.Balance, account.Currency),
+	})
 }
 
 func (a *accountImplement) Mutation(c *gin.Context) {
@@ -376,50 +385,42 @@ func (a *accountImplement) Mutation(c *gin.Context) {
 		return
 	}
 
-	var transactions []model.Transaction
-	var startDate, endDate time.Time
-	var err error
-
-	// Mengambil start_date dan end_date dari query parameter
-	startDateStr := c.DefaultQuery("start_date", "")
-	endDateStr := c.DefaultQuery("end_date", "")
-
-	// Jika ada start_date, parse menjadi time.Time
-	if startDateStr != "" {
-		startDate, err = time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
-			return
-		}
-	}
-
-	// Jika ada end_date, parse menjadi time.Time
-	if endDateStr != "" {
-		endDate, err = time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
-			return
-		}
+	// Ambil filter, sort dan paginasi dari query parameter
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	// Ambil transaksi yang melibatkan akun sebagai pengirim atau penerima dan sesuaikan dengan tanggal
-	query := a.db.Where("from_account_id = ? OR to_account_id = ?", accountID, accountID)
-
-	if !startDate.IsZero() {
-		query = query.Where("transaction_date >= ?", startDate)
+	limit, err := pageLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if !endDate.IsZero() {
-		query = query.Where("transaction_date <= ?", endDate)
+	cursor, err := decodeCursor(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	err = query.Order("transaction_date DESC").Limit(10).Find(&transactions).Error
+	mutations, err := a.accounts.Mutations(accountID.(int64), filter, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions"})
+		switch {
+		case errors.Is(err, errInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAccountNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions"})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": transactions})
+	page := pageInfo{Limit: limit, HasMore: mutations.Next != nil}
+	if mutations.Next != nil {
+		page.NextCursor = encodeCursor(*mutations.Next)
+	}
+	c.JSON(http.StatusOK, paginated(mutations.Items, page))
 }
 
 // func (a *accountImplement) Mutation(c *gin.Context) {
@@ -441,3 +442,197 @@ func (a *accountImplement) Mutation(c *gin.Context) {
 
 // 	c.JSON(http.StatusOK, gin.H{"data": transactions})
 // }
+
+// Handler for "GET /account/statement?from=2024-01-01&to=2024-12-31&format=csv|pdf"
+func (a *accountImplement) Statement(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format"})
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format"})
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or pdf"})
+		return
+	}
+
+	var account model.Account
+	if err := a.db.Where("account_id = ?", accountID).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	// Saldo awal dihitung dari jurnal sebelum tanggal from
+	var openingBalance int64
+	err = a.db.Model(&model.JournalLine{}).
+		Joins("JOIN journal_entries ON journal_entries.journal_entry_id = journal_lines.journal_entry_id").
+		Where("journal_lines.account_id = ? AND journal_entries.created_at < ?", accountID, from).
+		Select("COALESCE(SUM(journal_lines.credit - journal_lines.debit), 0)").
+		Scan(&openingBalance).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate opening balance"})
+		return
+	}
+
+	// Baris statement diambil dari jurnal yang sama dengan saldo awal, sehingga entry
+	// tanpa transaksi (saldo awal, penyesuaian) ikut tercatat dan saldo akhir sama
+	// dengan saldo akun. Diurutkan dari yang terlama; tanggal to inklusif.
+	endOfDay := to.Add(24*time.Hour - time.Nanosecond)
+	rows, err := a.db.Model(&model.JournalLine{}).
+		Joins("JOIN journal_entries ON journal_entries.journal_entry_id = journal_lines.journal_entry_id").
+		Joins(`LEFT JOIN "transaction" ON "transaction".transaction_id = journal_entries.transaction_id`).
+		Where("journal_lines.account_id = ? AND journal_entries.created_at >= ? AND journal_entries.created_at <= ?", accountID, from, endOfDay).
+		Select(`journal_entries.created_at, journal_entries.transaction_id, journal_entries.description,
+			journal_lines.credit - journal_lines.debit AS amount,
+			COALESCE("transaction".type, '') AS type, "transaction".from_account_id, "transaction".to_account_id, "transaction".reversal_of_id`).
+		Order("journal_entries.created_at, journal_lines.journal_line_id").
+		Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions"})
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("statement-%d-%s-%s.%s", accountID, from.Format("20060102"), to.Format("20060102"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	var writer statement.Writer
+	if format == "pdf" {
+		c.Header("Content-Type", "application/pdf")
+		writer = statement.NewPDFWriter(c.Writer)
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		writer = statement.NewCSVWriter(c.Writer)
+	}
+	c.Status(http.StatusOK)
+
+	err = writer.WriteHeader(statement.Header{
+		AccountID:      account.AccountID,
+		Name:           account.Name,
+		Currency:       account.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: openingBalance,
+		GeneratedAt:    time.Now(),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Baca baris satu per satu supaya riwayat panjang tidak dimuat sekaligus ke memori
+	balance := openingBalance
+	for rows.Next() {
+		var row statementRow
+		if err := a.db.ScanRows(rows, &row); err != nil {
+			c.Error(err)
+			return
+		}
+		balance += row.Amount
+
+		line := statement.Line{
+			Date:        row.CreatedAt,
+			Description: row.Description,
+			Amount:      row.Amount,
+			Balance:     balance,
+		}
+		if row.TransactionID != nil {
+			line.TransactionID = *row.TransactionID
+			line.Description = statementDescription(model.Transaction{
+				Type:          row.Type,
+				FromAccountID: row.FromAccountID,
+				ToAccountID:   row.ToAccountID,
+				ReversalOfID:  row.ReversalOfID,
+			}, accountID)
+		}
+		if err := writer.WriteLine(line); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := writer.Close(balance); err != nil {
+		c.Error(err)
+	}
+}
+
+// Handler for "GET /account/limit", menampilkan limit efektif dan pemakaiannya
+func (a *accountImplement) Limit(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var account model.Account
+	if err := a.db.Where("account_id = ?", accountID).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	policy, err := limits.Resolve(a.db, account)
+	if errors.Is(err, limits.ErrCurrencyNotConfigured) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve limits"})
+		return
+	}
+	daily, monthly, err := limits.Usage(a.db, accountID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve limit usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"tier":     account.Tier,
+			"currency": account.Currency,
+			"limits":   policy,
+			"usage": gin.H{
+				"daily":   daily,
+				"monthly": monthly,
+			},
+		},
+	})
+}
+
+// statementRow adalah satu journal line akun beserta transaksi asalnya (jika ada)
+type statementRow struct {
+	CreatedAt     time.Time
+	TransactionID *int64
+	Description   string
+	Amount        int64
+	Type          string
+	FromAccountID *int64
+	ToAccountID   *int64
+	ReversalOfID  *int64
+}
+
+// statementDescription membuat keterangan singkat untuk satu baris statement
+func statementDescription(transaction model.Transaction, accountID int64) string {
+	switch {
+	case transaction.Type == model.TransactionTypeReversal && transaction.ReversalOfID != nil:
+		return fmt.Sprintf("Reversal of #%d", *transaction.ReversalOfID)
+	case transaction.Type == model.TransactionTypeTopUp:
+		return "Top-up"
+	case transaction.FromAccountID != nil && *transaction.FromAccountID == accountID && transaction.ToAccountID != nil:
+		return fmt.Sprintf("Transfer to #%d", *transaction.ToAccountID)
+	case transaction.FromAccountID != nil:
+		return fmt.Sprintf("Transfer from #%d", *transaction.FromAccountID)
+	}
+	return "Transaction"
+}
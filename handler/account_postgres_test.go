@@ -0,0 +1,67 @@
+//go:build postgres
+
+package handler
+
+import (
+	"fmt"
+	"os"
+	"task-golang-batch2/migrations"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+	"task-golang-batch2/service"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestTransferConcurrentConservesMoneyPostgres menguji penguncian baris yang
+// sebenarnya. Jalankan lewat `make test-postgres` dengan TEST_DATABASE berisi DSN
+// database kosong untuk test; migrasi dijalankan otomatis.
+func TestTransferConcurrentConservesMoneyPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE must be set when running with -tags postgres")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrations.Up(db, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	accounts := service.NewAccountService(repository.NewGorm(db), nil)
+	suffix := time.Now().UnixNano()
+	var ids []int64
+	for i := 0; i < 4; i++ {
+		account := model.Account{Name: fmt.Sprintf("hammer %d %d", suffix, i), Currency: "IDR", Tier: "premium"}
+		if err := db.Create(&account).Error; err != nil {
+			t.Fatal(err)
+		}
+		if _, err := accounts.TopUp(account.AccountID, openingBalance); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, account.AccountID)
+	}
+
+	if succeeded := hammer(t, transferRouter(accounts), ids); succeeded == 0 {
+		t.Fatal("no transfer succeeded")
+	}
+
+	var total, journalTotal int64
+	if err := db.Model(&model.Account{}).Where("account_id IN ?", ids).
+		Select("COALESCE(SUM(balance), 0)").Scan(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&model.JournalLine{}).Where("account_id IN ?", ids).
+		Select("COALESCE(SUM(credit - debit), 0)").Scan(&journalTotal).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(openingBalance * len(ids)); total != want || journalTotal != want {
+		t.Errorf("balance total = %d, journal total = %d, want %d", total, journalTotal, want)
+	}
+}
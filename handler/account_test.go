@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+	"task-golang-batch2/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	hammerWorkers   = 16
+	hammerTransfers = 50 // per worker
+	openingBalance  = 1000
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// transferRouter memasang POST /account/transfer; pengirim diambil dari header
+// X-Test-Account sebagai pengganti AuthMiddleware
+func transferRouter(accounts service.AccountService) *gin.Engine {
+	r := gin.New()
+	r.POST("/account/transfer", func(c *gin.Context) {
+		accountID, _ := strconv.ParseInt(c.GetHeader("X-Test-Account"), 10, 64)
+		c.Set("account_id", accountID)
+		c.Set("role", model.RoleCustomer)
+	}, NewAccount(nil, accounts).Transfer)
+	return r
+}
+
+// hammer mengirim transfer acak antar ids secara paralel. Hanya sukses atau
+// saldo tidak cukup yang boleh terjadi; error lain (misal deadlock) menggagalkan test.
+func hammer(t *testing.T, r *gin.Engine, ids []int64) (succeeded int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for worker := 0; worker < hammerWorkers; worker++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			random := rand.New(rand.NewSource(seed))
+			for i := 0; i < hammerTransfers; i++ {
+				from := ids[random.Intn(len(ids))]
+				to := ids[random.Intn(len(ids))]
+				for to == from {
+					to = ids[random.Intn(len(ids))]
+				}
+
+				form := url.Values{
+					"to_account_id": {strconv.FormatInt(to, 10)},
+					"amount":        {strconv.Itoa(1 + random.Intn(300))},
+				}
+				req := httptest.NewRequest(http.MethodPost, "/account/transfer", strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.Header.Set("X-Test-Account", strconv.FormatInt(from, 10))
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				switch w.Code {
+				case http.StatusOK:
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				case http.StatusUnprocessableEntity:
+					// Saldo tidak cukup atau limit, tetap konsisten
+				default:
+					t.Errorf("transfer %d -> %d: status %d: %s", from, to, w.Code, w.Body.String())
+				}
+			}
+		}(int64(worker))
+	}
+	wg.Wait()
+	return succeeded
+}
+
+func TestTransferConcurrentConservesMoney(t *testing.T) {
+	store := repository.NewMemory()
+	accounts := service.NewAccountService(store, nil)
+
+	var ids []int64
+	for i := 0; i < 4; i++ {
+		account := store.AddAccount(model.Account{Name: fmt.Sprintf("hammer %d", i), Currency: "IDR", Tier: "premium"})
+		if _, err := accounts.TopUp(account.AccountID, openingBalance); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, account.AccountID)
+	}
+
+	succeeded := hammer(t, transferRouter(accounts), ids)
+	if succeeded == 0 {
+		t.Fatal("no transfer succeeded")
+	}
+
+	var total int64
+	for _, id := range ids {
+		account, err := store.Accounts().Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if account.Balance < 0 {
+			t.Errorf("account %d has negative balance %d", id, account.Balance)
+		}
+		total += account.Balance
+	}
+	if want := int64(openingBalance * len(ids)); total != want {
+		t.Errorf("total balance = %d, want %d", total, want)
+	}
+
+	// Setiap entry seimbang dan jurnal per akun sama dengan saldonya
+	journal := map[int64]int64{}
+	for _, entry := range store.Entries() {
+		var debit, credit int64
+		for _, line := range entry.Lines {
+			debit += line.Debit
+			credit += line.Credit
+			journal[line.AccountID] += line.Credit - line.Debit
+		}
+		if debit != credit {
+			t.Errorf("entry %d is unbalanced: debit %d, credit %d", entry.JournalEntryID, debit, credit)
+		}
+	}
+	var journalTotal int64
+	for _, id := range ids {
+		account, _ := store.Accounts().Get(id)
+		if journal[id] != account.Balance {
+			t.Errorf("account %d: journal %d, balance %d", id, journal[id], account.Balance)
+		}
+		journalTotal += journal[id]
+	}
+	if journalTotal != total {
+		t.Errorf("journal total = %d, want %d", journalTotal, total)
+	}
+}
+
+// TestServiceTransferRequiresAllowedSource memastikan API key tidak bisa
+// memindahkan uang dari akun yang tidak ada di allow-list principal-nya
+func TestServiceTransferRequiresAllowedSource(t *testing.T) {
+	const principalID = 7
+
+	store := repository.NewMemory()
+	accounts := service.NewAccountService(store, nil)
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		account := store.AddAccount(model.Account{Name: fmt.Sprintf("service %d", i), Currency: "IDR", Tier: "premium"})
+		if _, err := accounts.TopUp(account.AccountID, openingBalance); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, account.AccountID)
+	}
+	allowed, unrelated, recipient := ids[0], ids[1], ids[2]
+	store.AllowPrincipal(principalID, allowed)
+
+	r := gin.New()
+	r.POST("/account/transfer", func(c *gin.Context) {
+		c.Set("role", model.RoleService)
+		c.Set("service_principal_id", int64(principalID))
+	}, NewAccount(nil, accounts).Transfer)
+
+	transfer := func(from int64) *httptest.ResponseRecorder {
+		form := url.Values{
+			"from_account_id": {strconv.FormatInt(from, 10)},
+			"to_account_id":   {strconv.FormatInt(recipient, 10)},
+			"amount":          {"100"},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/account/transfer", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := transfer(unrelated); w.Code != http.StatusForbidden {
+		t.Fatalf("transfer from unrelated account: status %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+	if w := transfer(allowed); w.Code != http.StatusOK {
+		t.Fatalf("transfer from allowed account: status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	want := map[int64]int64{allowed: openingBalance - 100, unrelated: openingBalance, recipient: openingBalance + 100}
+	for id, balance := range want {
+		account, err := store.Accounts().Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if account.Balance != balance {
+			t.Errorf("account %d balance = %d, want %d", id, account.Balance, balance)
+		}
+	}
+}
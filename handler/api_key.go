@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"task-golang-batch2/apikey"
+	"task-golang-batch2/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type APIKeyInterface interface {
+	CreatePrincipal(*gin.Context)
+	ListPrincipals(*gin.Context)
+	DisablePrincipal(*gin.Context)
+	GrantAccount(*gin.Context)
+	ListAccounts(*gin.Context)
+	RevokeAccount(*gin.Context)
+	Create(*gin.Context)
+	List(*gin.Context)
+	Revoke(*gin.Context)
+}
+
+type apiKeyImplement struct {
+	db *gorm.DB
+}
+
+func NewAPIKey(db *gorm.DB) APIKeyInterface {
+	return &apiKeyImplement{
+		db: db,
+	}
+}
+
+type servicePrincipalPayload struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// Handler for "POST /admin/service/create"
+func (a *apiKeyImplement) CreatePrincipal(c *gin.Context) {
+	var payload servicePrincipalPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	principal := model.ServicePrincipal{
+		Name:        payload.Name,
+		Description: payload.Description,
+		CreatedBy:   c.GetInt64("auth_id"),
+	}
+	if err := a.db.Create(&principal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service principal: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    principal,
+	})
+}
+
+// Handler for "GET /admin/service/list"
+func (a *apiKeyImplement) ListPrincipals(c *gin.Context) {
+	var principals []model.ServicePrincipal
+	if err := a.db.Order("service_principal_id").Find(&principals).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": principals,
+	})
+}
+
+// Handler for "PATCH /admin/service/disable/:id", semua key milik principal ikut tidak berlaku
+func (a *apiKeyImplement) DisablePrincipal(c *gin.Context) {
+	result := a.db.Model(&model.ServicePrincipal{}).
+		Where("service_principal_id = ? AND disabled_at IS NULL", c.Param("id")).
+		Update("disabled_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Disable success"})
+}
+
+type principalAccountPayload struct {
+	ServicePrincipalID int64 `json:"service_principal_id" binding:"required"`
+	AccountID          int64 `json:"account_id" binding:"required"`
+}
+
+// Handler for "POST /admin/service/account/grant", mengizinkan principal mendebit akun
+func (a *apiKeyImplement) GrantAccount(c *gin.Context) {
+	var payload principalAccountPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	if err := a.db.First(&model.ServicePrincipal{}, "service_principal_id = ?", payload.ServicePrincipalID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service principal not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var account model.Account
+	if err := a.db.First(&account, "account_id = ?", payload.AccountID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if account.IsSystem {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "System accounts cannot be granted"})
+		return
+	}
+
+	grant := model.ServicePrincipalAccount{
+		ServicePrincipalID: payload.ServicePrincipalID,
+		AccountID:          payload.AccountID,
+		CreatedBy:          c.GetInt64("auth_id"),
+	}
+	// Grant yang sudah ada dibiarkan apa adanya
+	if err := a.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&grant).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant account: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Grant success"})
+}
+
+// Handler for "GET /admin/service/account/list/:id"
+func (a *apiKeyImplement) ListAccounts(c *gin.Context) {
+	var grants []model.ServicePrincipalAccount
+	if err := a.db.Where("service_principal_id = ?", c.Param("id")).Order("account_id").Find(&grants).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": grants,
+	})
+}
+
+// Handler for "DELETE /admin/service/account/revoke/:id/:account_id"
+func (a *apiKeyImplement) RevokeAccount(c *gin.Context) {
+	result := a.db.Where("service_principal_id = ? AND account_id = ?", c.Param("id"), c.Param("account_id")).
+		Delete(&model.ServicePrincipalAccount{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Revoke success"})
+}
+
+type apiKeyCreatePayload struct {
+	ServicePrincipalID int64      `json:"service_principal_id" binding:"required"`
+	Name               string     `json:"name" binding:"required"`
+	Scopes             []string   `json:"scopes" binding:"required"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// Handler for "POST /admin/apikey/create". Key lengkap hanya dikembalikan sekali di sini.
+func (a *apiKeyImplement) Create(c *gin.Context) {
+	var payload apiKeyCreatePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	scopes, err := apikey.NormalizeScopes(payload.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "valid_scopes": model.Scopes})
+		return
+	}
+	if payload.ExpiresAt != nil && payload.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	var principal model.ServicePrincipal
+	if err := a.db.First(&principal, "service_principal_id = ?", payload.ServicePrincipalID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service principal not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if principal.DisabledAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Service principal is disabled"})
+		return
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate api key"})
+		return
+	}
+
+	record := model.APIKey{
+		ServicePrincipalID: principal.ServicePrincipalID,
+		Name:               payload.Name,
+		Prefix:             prefix,
+		KeyHash:            hash,
+		Scopes:             scopes,
+		CreatedBy:          c.GetInt64("auth_id"),
+		ExpiresAt:          payload.ExpiresAt,
+	}
+	if err := a.db.Create(&record).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create api key: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success, store the key now: it will not be shown again",
+		"data":    record,
+		"key":     key,
+	})
+}
+
+// Handler for "GET /admin/apikey/list", bisa difilter dengan ?service_principal_id=
+func (a *apiKeyImplement) List(c *gin.Context) {
+	var keys []model.APIKey
+	query := a.db.Order("api_key_id")
+	if principalID := c.Query("service_principal_id"); principalID != "" {
+		query = query.Where("service_principal_id = ?", principalID)
+	}
+	if err := query.Find(&keys).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": keys,
+	})
+}
+
+// Handler for "DELETE /admin/apikey/revoke/:id"
+func (a *apiKeyImplement) Revoke(c *gin.Context) {
+	result := a.db.Model(&model.APIKey{}).
+		Where("api_key_id = ? AND revoked_at IS NULL", c.Param("id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Revoke success"})
+}
@@ -1,14 +1,27 @@
 package handler
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"task-golang-batch2/keyset"
+	"task-golang-batch2/lockout"
 	"task-golang-batch2/model"
+	"task-golang-batch2/notify"
+	"task-golang-batch2/password"
+	"task-golang-batch2/service"
+	"task-golang-batch2/totp"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -17,23 +30,56 @@ type AuthInterface interface {
 	Login(*gin.Context)
 	Upsert(*gin.Context)
 	ChangePassword(*gin.Context)
+	Refresh(*gin.Context)
+	Logout(*gin.Context)
+	LoginTwoFactor(*gin.Context)
+	EnrollTwoFactor(*gin.Context)
+	VerifyTwoFactor(*gin.Context)
+	DisableTwoFactor(*gin.Context)
+	JWKS(*gin.Context)
+	ForgotPassword(*gin.Context)
+	ResetPassword(*gin.Context)
+	Register(*gin.Context)
+	Sessions(*gin.Context)
+	RevokeSession(*gin.Context)
+}
+
+const totpIssuer = "Digi"
+
+// TokenTTL adalah masa berlaku token yang diterbitkan handler auth
+type TokenTTL struct {
+	// Access token dibuat singkat; sesi diperpanjang lewat refresh token
+	Access  time.Duration
+	Refresh time.Duration
+	// Batas waktu untuk memasukkan kode 2FA setelah password benar
+	Challenge     time.Duration
+	PasswordReset time.Duration
 }
 
 type authImplement struct {
-	db         *gorm.DB
-	signingKey []byte
+	db        *gorm.DB
+	keys      *keyset.Set
+	notifier  notify.Notifier
+	passwords *password.Manager
+	logins    service.AuthService
+	ttl       TokenTTL
 }
 
-func NewAuth(db *gorm.DB, signingKey []byte) AuthInterface {
+func NewAuth(db *gorm.DB, keys *keyset.Set, notifier notify.Notifier, passwords *password.Manager, logins service.AuthService, ttl TokenTTL) AuthInterface {
 	return &authImplement{
 		db,
-		signingKey,
+		keys,
+		notifier,
+		passwords,
+		logins,
+		ttl,
 	}
 }
 
 type authLoginPayload struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name"` // Opsional, ditampilkan di daftar session
 }
 
 func (a *authImplement) Login(c *gin.Context) {
@@ -48,45 +94,144 @@ func (a *authImplement) Login(c *gin.Context) {
 		return
 	}
 
-	// Validate username to get auth data
-	auth := model.Auth{}
-	if err := a.db.Where("username = ?",
-		payload.Username).
-		First(&auth).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	// Tolak lebih dulu jika username atau IP sedang di-backoff / dikunci. Percobaan ini
+	// langsung dihitung sehingga request paralel tidak bisa melewati batas.
+	if !a.checkThrottle(c, lockout.User(payload.Username), lockout.IP(c.ClientIP())) {
+		return
+	}
+
+	// Validate username and password
+	result, err := a.logins.Login(payload.Username, payload.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			var authID *int64
+			if result.Auth != nil {
+				authID = &result.Auth.AuthID
+			}
+			a.recordLoginFailure(c, payload.Username, authID)
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 				"error": "Login not valid",
 			})
 			return
 		}
 
+		a.releaseAttempt(c, payload.Username)
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
+	auth := result.Auth
+
+	// Jika 2FA aktif, password saja belum cukup: kirim challenge untuk langkah kedua.
+	// Password benar, jadi percobaan ini tidak dihitung gagal.
+	if result.TwoFactorRequired {
+		a.releaseAttempt(c, payload.Username)
+		challenge, err := a.createChallenge(auth)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": err,
+			})
+			return
+		}
 
-	// Validate password
-	if err := bcrypt.CompareHashAndPassword([]byte(auth.Password), []byte(payload.Password)); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "Login not valid",
+		c.JSON(http.StatusOK, gin.H{
+			"message":             "Two-factor authentication required",
+			"two_factor_required": true,
+			"challenge":           challenge,
 		})
 		return
 	}
 
 	// Login is valid
-	token, err := a.createJWT(&auth)
+	a.loginSuccess(c, auth, payload.DeviceName)
+}
+
+// checkThrottle memesan satu percobaan untuk semua subject, atau membalas 429 dengan
+// header Retry-After jika salah satu subject masih harus menunggu. Mengembalikan
+// false jika request sudah dihentikan.
+func (a *authImplement) checkThrottle(c *gin.Context, subjects ...lockout.Subject) bool {
+	wait, err := lockout.Reserve(a.db, time.Now(), subjects...)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": err,
+			"error": "Failed to check login attempts",
+		})
+		return false
+	}
+	if wait > 0 {
+		seconds := int64(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error":       "Too many failed login attempts, try again later",
+			"retry_after": seconds,
+		})
+		return false
+	}
+	return true
+}
+
+// releaseAttempt membatalkan percobaan yang dipesan checkThrottle untuk username dan IP klien
+func (a *authImplement) releaseAttempt(c *gin.Context, username string) {
+	for _, subject := range []lockout.Subject{lockout.User(username), lockout.IP(c.ClientIP())} {
+		if err := lockout.Release(a.db, subject); err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to release login attempt", "subject", subject.Key, "error", err)
+		}
+	}
+}
+
+// recordLoginFailure mencatat percobaan gagal untuk username dan IP klien
+func (a *authImplement) recordLoginFailure(c *gin.Context, username string, authID *int64) {
+	failure := lockout.Failure{AuthID: authID, Username: username, IPAddress: c.ClientIP()}
+	now := time.Now()
+	for _, subject := range []lockout.Subject{lockout.User(username), lockout.IP(c.ClientIP())} {
+		if _, err := lockout.RecordFailure(a.db, now, subject, failure); err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to record login failure", "subject", subject.Key, "error", err)
+		}
+	}
+}
+
+// resetAttempts dipanggil setelah password (dan 2FA) terbukti benar: hitungan gagal
+// username di-reset dan percobaan yang dipesan untuk IP dibatalkan
+func (a *authImplement) resetAttempts(c *gin.Context, auth *model.Auth) {
+	if err := lockout.Reset(a.db, lockout.User(auth.Username)); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to reset login attempts", "auth_id", auth.AuthID, "error", err)
+	}
+	if err := lockout.Release(a.db, lockout.IP(c.ClientIP())); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to release login attempt", "auth_id", auth.AuthID, "error", err)
+	}
+}
+
+// loginSuccess membuat session baru untuk perangkat ini lalu menerbitkan access token
+// dan refresh token (family milik session tersebut)
+func (a *authImplement) loginSuccess(c *gin.Context, auth *model.Auth, deviceName string) {
+	// Login berhasil penuh (termasuk 2FA)
+	a.resetAttempts(c, auth)
+
+	var token, refreshToken string
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		session, err := startSession(tx, c, auth.AuthID, deviceName)
+		if err != nil {
+			return err
+		}
+		if refreshToken, err = a.createRefreshToken(tx, auth.AuthID, session.FamilyID); err != nil {
+			return err
+		}
+		token, err = a.createJWT(auth, session.SessionID)
+		return err
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create session",
 		})
 		return
 	}
 
 	// Success response
 	c.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("%v Login Sukses", payload.Username),
-		"data":    token,
+		"message":       fmt.Sprintf("%v Login Sukses", auth.Username),
+		"data":          token,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(a.ttl.Access.Seconds()),
 	})
 }
 
@@ -108,8 +253,14 @@ func (a *authImplement) Upsert(c *gin.Context) {
 		return
 	}
 
+	// Password baru harus memenuhi policy
+	if err := a.passwords.Validate(payload.Username, payload.Password); err != nil {
+		respondPasswordPolicy(c, err)
+		return
+	}
+
 	// Hash Given Password
-	hashed, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+	hashed, err := a.passwords.Hash(payload.Password)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"error": err,
@@ -141,14 +292,28 @@ func (a *authImplement) Upsert(c *gin.Context) {
 	}
 
 	// Upsert auth data (Insert or Update if already exists)
-	result := a.db.Clauses(
-		clause.OnConflict{
-			DoUpdates: clause.AssignmentColumns([]string{"username", "password"}),
-			Columns:   []clause.Column{{Name: "account_id"}},
-		}).Create(&auth)
-	if result.Error != nil {
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(
+			clause.OnConflict{
+				// Password berubah, sesi lama ikut tidak berlaku
+				DoUpdates: append(clause.AssignmentColumns([]string{"username", "password"}),
+					clause.Assignment{Column: clause.Column{Name: "token_version"}, Value: gorm.Expr("auths.token_version + 1")}),
+				Columns: []clause.Column{{Name: "account_id"}},
+			}).Create(&auth).Error
+		if err != nil {
+			return err
+		}
+
+		// Sama seperti reset password: refresh token dan sesi yang ada ikut dicabut
+		owned := "auth_id IN (SELECT auth_id FROM auths WHERE account_id = ?)"
+		if err := revokeSessions(tx, owned, payload.AccountID); err != nil {
+			return err
+		}
+		return revokeRefreshTokens(tx, owned, payload.AccountID)
+	})
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": result.Error.Error(),
+			"error": err.Error(),
 		})
 		return
 	}
@@ -165,8 +330,8 @@ func (a *authImplement) ChangePassword(c *gin.Context) {
 	// Definisikan struktur request
 	type ChangePasswordRequest struct {
 		OldPassword     string `json:"oldPassword" binding:"required"`
-		NewPassword     string `json:"newPassword" binding:"required,min=6"`
-		ConfirmPassword string `json:"confirmPassword" binding:"required,min=6"`
+		NewPassword     string `json:"newPassword" binding:"required"`
+		ConfirmPassword string `json:"confirmPassword" binding:"required"`
 	}
 
 	// Bind request JSON ke struct
@@ -195,7 +360,7 @@ func (a *authImplement) ChangePassword(c *gin.Context) {
 	}
 
 	// Validasi password lama
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+	if valid, _, err := a.passwords.Verify(user.Password, req.OldPassword); err != nil || !valid {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Old password is incorrect"})
 		return
 	}
@@ -206,16 +371,34 @@ func (a *authImplement) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Password baru harus memenuhi policy
+	if err := a.passwords.Validate(user.Username, req.NewPassword); err != nil {
+		respondPasswordPolicy(c, err)
+		return
+	}
+
 	// Hash password baru
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := a.passwords.Hash(req.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash new password"})
 		return
 	}
 
-	// Update password di database
-	user.Password = string(hashedPassword)
-	if err := a.db.Save(&user).Error; err != nil {
+	// Update password di database dan putus semua sesi: versi token dinaikkan
+	// sehingga access token lama ditolak, dan semua refresh token dicabut
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"password":      string(hashedPassword),
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, "auth_id = ?", user.AuthID); err != nil {
+			return err
+		}
+		return revokeRefreshTokens(tx, "auth_id = ?", user.AuthID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
 		return
 	}
@@ -224,19 +407,22 @@ func (a *authImplement) ChangePassword(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
 }
 
-func (a *authImplement) createJWT(auth *model.Auth) (string, error) {
-	// Create the jwt token signer
-	token := jwt.New(jwt.SigningMethodHS256)
-
+func (a *authImplement) createJWT(auth *model.Auth, sessionID int64) (string, error) {
 	// Add claims data or additional data (avoid to put secret information in the payload or header elements)
-	claims := token.Claims.(jwt.MapClaims)
+	claims := jwt.MapClaims{}
 	claims["auth_id"] = auth.AuthID
 	claims["account_id"] = auth.AccountID
 	claims["username"] = auth.Username
-	claims["exp"] = time.Now().Add(time.Hour * 72).Unix() // Token expires in 72 hours
-
-	// Encode
-	tokenString, err := token.SignedString(a.signingKey)
+	claims["role"] = auth.Role
+	claims["typ"] = "access"
+	claims["jti"] = randomToken(16)
+	claims["ver"] = auth.TokenVersion
+	claims["sid"] = sessionID
+	claims["iat"] = time.Now().Unix()
+	claims["exp"] = time.Now().Add(a.ttl.Access).Unix()
+
+	// Encode dengan kunci aktif dari keyset (header kid ikut diisi)
+	tokenString, err := a.keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -244,3 +430,405 @@ func (a *authImplement) createJWT(auth *model.Auth) (string, error) {
 	// Return the token
 	return tokenString, nil
 }
+
+// randomToken menghasilkan string acak base64url dari n byte
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// createRefreshToken menyimpan hash refresh token baru. familyID kosong berarti login baru.
+func (a *authImplement) createRefreshToken(tx *gorm.DB, authID int64, familyID string) (string, error) {
+	if familyID == "" {
+		familyID = randomToken(16)
+	}
+
+	token := randomToken(32)
+	record := model.RefreshToken{
+		AuthID:    authID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(a.ttl.Refresh),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func revokeRefreshTokens(tx *gorm.DB, query string, args ...interface{}) error {
+	return tx.Model(&model.RefreshToken{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).Error
+}
+
+type authRefreshPayload struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+var errRefreshTokenInvalid = errors.New("refresh token invalid")
+
+// Refresh menukar refresh token dengan access token dan refresh token baru (rotasi).
+// Refresh token yang sudah pernah dipakai dianggap bocor, seluruh family-nya dicabut.
+func (a *authImplement) Refresh(c *gin.Context) {
+	var payload authRefreshPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	var accessToken, refreshToken string
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var record model.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashToken(payload.RefreshToken)).
+			First(&record).Error
+		if err != nil {
+			return errRefreshTokenInvalid
+		}
+
+		if record.RevokedAt != nil {
+			// Token lama dipakai ulang: cabut session dan semua token di family ini
+			if err := revokeSessions(tx, "family_id = ?", record.FamilyID); err != nil {
+				return err
+			}
+			if err := revokeRefreshTokens(tx, "family_id = ?", record.FamilyID); err != nil {
+				return err
+			}
+			return errRefreshTokenInvalid
+		}
+		if time.Now().After(record.ExpiresAt) {
+			return errRefreshTokenInvalid
+		}
+
+		var auth model.Auth
+		if err := tx.Where("auth_id = ?", record.AuthID).First(&auth).Error; err != nil {
+			return errRefreshTokenInvalid
+		}
+
+		session, err := sessionForRefresh(tx, c, &record)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&record).Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if refreshToken, err = a.createRefreshToken(tx, auth.AuthID, record.FamilyID); err != nil {
+			return err
+		}
+		accessToken, err = a.createJWT(&auth, session.SessionID)
+		return err
+	})
+
+	if err != nil {
+		if errors.Is(err, errRefreshTokenInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":          accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(a.ttl.Access.Seconds()),
+	})
+}
+
+type authLogoutPayload struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout mencabut access token yang sedang dipakai dan (jika dikirim) refresh token-nya
+func (a *authImplement) Logout(c *gin.Context) {
+	var payload authLogoutPayload
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	authID := c.GetInt64("auth_id")
+	jti := c.GetString("jti")
+	expiresAt := c.GetTime("token_exp")
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(a.ttl.Access)
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error; err != nil {
+			return err
+		}
+
+		// Session yang sedang dipakai ikut berakhir
+		if err := revokeSessions(tx, "session_id = ? AND auth_id = ?", c.GetInt64("session_id"), authID); err != nil {
+			return err
+		}
+
+		if payload.RefreshToken != "" {
+			var record model.RefreshToken
+			err := tx.Where("token_hash = ? AND auth_id = ?", hashToken(payload.RefreshToken), authID).First(&record).Error
+			if err == nil {
+				if err := revokeRefreshTokens(tx, "family_id = ?", record.FamilyID); err != nil {
+					return err
+				}
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		// Bersihkan denylist yang sudah kadaluarsa
+		return tx.Where("expires_at < ?", time.Now()).Delete(&model.RevokedToken{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout success"})
+}
+
+// createChallenge membuat token singkat untuk langkah kedua login 2FA.
+// Token ini bertipe "2fa" sehingga tidak diterima AuthMiddleware sebagai access token.
+func (a *authImplement) createChallenge(auth *model.Auth) (string, error) {
+	return a.keys.Sign(jwt.MapClaims{
+		"typ":     "2fa",
+		"auth_id": auth.AuthID,
+		"ver":     auth.TokenVersion,
+		"exp":     time.Now().Add(a.ttl.Challenge).Unix(),
+	})
+}
+
+type authLoginTwoFactorPayload struct {
+	Challenge  string `json:"challenge" binding:"required"`
+	Code       string `json:"code" binding:"required"` // Kode TOTP atau kode cadangan
+	DeviceName string `json:"device_name"`
+}
+
+// LoginTwoFactor adalah langkah kedua login: menukar challenge + kode 2FA dengan token
+func (a *authImplement) LoginTwoFactor(c *gin.Context) {
+	var payload authLoginTwoFactorPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	claims, err := a.keys.Parse(payload.Challenge)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Challenge invalid or expired"})
+		return
+	}
+	authID, _ := claims["auth_id"].(float64)
+	version, _ := claims["ver"].(float64)
+	if claims["typ"] != "2fa" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Challenge invalid or expired"})
+		return
+	}
+
+	var auth model.Auth
+	if err := a.db.Where("auth_id = ?", int64(authID)).First(&auth).Error; err != nil ||
+		auth.TokenVersion != int64(version) || !auth.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Challenge invalid or expired"})
+		return
+	}
+
+	// Kode 2FA yang salah ikut dihitung sebagai percobaan gagal
+	if !a.checkThrottle(c, lockout.User(auth.Username), lockout.IP(c.ClientIP())) {
+		return
+	}
+
+	ok, err := totp.Verify(a.db, &auth, payload.Code)
+	if err != nil {
+		a.releaseAttempt(c, auth.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !ok {
+		a.recordLoginFailure(c, auth.Username, &auth.AuthID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	a.loginSuccess(c, &auth, payload.DeviceName)
+}
+
+// EnrollTwoFactor membuat secret TOTP baru (belum aktif) dan URI untuk QR code
+func (a *authImplement) EnrollTwoFactor(c *gin.Context) {
+	var auth model.Auth
+	if err := a.db.Where("auth_id = ?", c.GetInt64("auth_id")).First(&auth).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if auth.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	if err := a.db.Model(&auth).Update("totp_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan the provisioning URI and verify a code to enable two-factor authentication",
+		"data": gin.H{
+			"secret":           secret,
+			"provisioning_uri": totp.ProvisioningURI(totpIssuer, auth.Username, secret),
+		},
+	})
+}
+
+type authTwoFactorCodePayload struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VerifyTwoFactor mengaktifkan 2FA setelah kode pertama benar dan mengembalikan kode cadangan
+func (a *authImplement) VerifyTwoFactor(c *gin.Context) {
+	var payload authTwoFactorCodePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	var auth model.Auth
+	if err := a.db.Where("auth_id = ?", c.GetInt64("auth_id")).First(&auth).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if auth.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication already enabled"})
+		return
+	}
+	if auth.TOTPSecret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Enroll two-factor authentication first"})
+		return
+	}
+
+	step, ok := totp.Validate(*auth.TOTPSecret, payload.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	var backupCodes []string
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&auth).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		backupCodes, err = totp.GenerateBackupCodes(tx, auth.AuthID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication enabled",
+		"data": gin.H{
+			"backup_codes": backupCodes,
+		},
+	})
+}
+
+type authDisableTwoFactorPayload struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactor mematikan 2FA; membutuhkan password dan kode 2FA yang valid
+func (a *authImplement) DisableTwoFactor(c *gin.Context) {
+	var payload authDisableTwoFactorPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	var auth model.Auth
+	if err := a.db.Where("auth_id = ?", c.GetInt64("auth_id")).First(&auth).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !auth.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	// Password dan kode yang salah dihitung seperti percobaan login gagal
+	if !a.checkThrottle(c, lockout.User(auth.Username), lockout.IP(c.ClientIP())) {
+		return
+	}
+
+	valid, _, err := a.passwords.Verify(auth.Password, payload.Password)
+	if err != nil {
+		a.releaseAttempt(c, auth.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify password"})
+		return
+	}
+	if !valid {
+		a.recordLoginFailure(c, auth.Username, &auth.AuthID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is incorrect"})
+		return
+	}
+
+	ok, err := totp.Verify(a.db, &auth, payload.Code)
+	if err != nil {
+		a.releaseAttempt(c, auth.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !ok {
+		a.recordLoginFailure(c, auth.Username, &auth.AuthID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+	a.resetAttempts(c, &auth)
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&auth).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    nil,
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("auth_id = ?", auth.AuthID).Delete(&model.BackupCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// Handler for "GET /.well-known/jwks.json", kunci publik untuk verifikasi token oleh service lain
+func (a *authImplement) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{
+		"keys": a.keys.JWKS(),
+	})
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FxInterface interface {
+	Create(*gin.Context)
+	List(*gin.Context)
+}
+
+type fxImplement struct {
+	db *gorm.DB
+}
+
+func NewFx(db *gorm.DB) FxInterface {
+	return &fxImplement{
+		db: db,
+	}
+}
+
+type fxCreatePayload struct {
+	BaseCurrency  string     `json:"base_currency" binding:"required"`
+	QuoteCurrency string     `json:"quote_currency" binding:"required"`
+	Rate          string     `json:"rate" binding:"required"`
+	EffectiveAt   *time.Time `json:"effective_at"`
+}
+
+// Handler for "POST /fx/create". Kurs lama tidak ditimpa supaya riwayatnya tetap ada.
+func (f *fxImplement) Create(c *gin.Context) {
+	var payload fxCreatePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	base, err := currency.Normalize(payload.BaseCurrency)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "base_currency: " + err.Error()})
+		return
+	}
+	quote, err := currency.Normalize(payload.QuoteCurrency)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "quote_currency: " + err.Error()})
+		return
+	}
+	if base == quote {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "base_currency and quote_currency must differ"})
+		return
+	}
+	if _, err := currency.ParseRate(payload.Rate); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate := model.FxRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          payload.Rate,
+		EffectiveAt:   time.Now(),
+	}
+	if payload.EffectiveAt != nil {
+		rate.EffectiveAt = *payload.EffectiveAt
+	}
+
+	if err := f.db.Create(&rate).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Success response
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    rate,
+	})
+}
+
+// Handler for "GET /fx/list", bisa difilter dengan ?base=USD&quote=IDR
+func (f *fxImplement) List(c *gin.Context) {
+	var rates []model.FxRate
+
+	query := f.db.Order("effective_at DESC")
+	if base := c.Query("base"); base != "" {
+		query = query.Where("base_currency = ?", base)
+	}
+	if quote := c.Query("quote"); quote != "" {
+		query = query.Where("quote_currency = ?", quote)
+	}
+
+	if err := query.Limit(100).Find(&rates).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Success response
+	c.JSON(http.StatusOK, gin.H{
+		"data": rates,
+	})
+}
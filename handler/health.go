@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"task-golang-batch2/health"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readyTimeout membatasi lama semua check readiness supaya probe tidak menggantung
+const readyTimeout = 3 * time.Second
+
+type HealthInterface interface {
+	Live(*gin.Context)
+	Ready(*gin.Context)
+}
+
+type healthImplement struct {
+	registry *health.Registry
+}
+
+func NewHealth(registry *health.Registry) HealthInterface {
+	return &healthImplement{
+		registry: registry,
+	}
+}
+
+// Handler for "GET /healthz", liveness: proses masih hidup dan bisa melayani HTTP
+func (h *healthImplement) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
+
+// Handler for "GET /readyz", readiness: database, skema dan worker siap.
+// Mengembalikan 503 jika ada check yang gagal atau server sedang shutdown.
+func (h *healthImplement) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	checks, ready := h.registry.Run(ctx)
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "unavailable",
+			"checks": checks,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"checks": checks,
+	})
+}
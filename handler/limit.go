@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LimitInterface interface {
+	Upsert(*gin.Context)
+	List(*gin.Context)
+	Delete(*gin.Context)
+}
+
+type limitImplement struct {
+	db *gorm.DB
+}
+
+func NewLimit(db *gorm.DB) LimitInterface {
+	return &limitImplement{
+		db: db,
+	}
+}
+
+type limitUpsertPayload struct {
+	Tier           *string `json:"tier"`
+	Currency       *string `json:"currency"` // Wajib untuk tier, tidak dipakai untuk override akun
+	AccountID      *int64  `json:"account_id"`
+	PerTransaction *int64  `json:"per_transaction"`
+	Daily          *int64  `json:"daily"`
+	Monthly        *int64  `json:"monthly"`
+}
+
+// Handler for "POST /admin/limit/upsert". Isi tier dan currency untuk default tier atau
+// account_id untuk override akun (nominal dalam mata uang akun).
+func (l *limitImplement) Upsert(c *gin.Context) {
+	var payload limitUpsertPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if (payload.Tier == nil) == (payload.AccountID == nil) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Exactly one of tier or account_id is required"})
+		return
+	}
+	if payload.Tier != nil {
+		if payload.Currency == nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "currency is required for tier limits"})
+			return
+		}
+		code, err := currency.Normalize(*payload.Currency)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		payload.Currency = &code
+	} else if payload.Currency != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "currency is only used for tier limits"})
+		return
+	}
+	for _, value := range []*int64{payload.PerTransaction, payload.Daily, payload.Monthly} {
+		if value != nil && *value <= 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Limits must be greater than zero"})
+			return
+		}
+	}
+
+	limit := model.TransferLimit{}
+	query := l.db
+	if payload.Tier != nil {
+		query = query.Where("tier = ? AND currency = ?", *payload.Tier, *payload.Currency)
+	} else {
+		query = query.Where("account_id = ?", *payload.AccountID)
+	}
+	if err := query.First(&limit).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit.Tier = payload.Tier
+	limit.Currency = payload.Currency
+	limit.AccountID = payload.AccountID
+	limit.PerTransaction = payload.PerTransaction
+	limit.Daily = payload.Daily
+	limit.Monthly = payload.Monthly
+
+	var err error
+	if limit.TransferLimitID == 0 {
+		err = l.db.Create(&limit).Error
+	} else {
+		err = l.db.Model(&limit).Select("per_transaction", "daily", "monthly").Updates(&limit).Error
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Success response
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Upsert success",
+		"data":    limit,
+	})
+}
+
+// Handler for "GET /admin/limit/list"
+func (l *limitImplement) List(c *gin.Context) {
+	var limits []model.TransferLimit
+
+	if err := l.db.Order("transfer_limit_id").Find(&limits).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Success response
+	c.JSON(http.StatusOK, gin.H{
+		"data": limits,
+	})
+}
+
+// Handler for "DELETE /admin/limit/delete/:id"
+func (l *limitImplement) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := l.db.Where("transfer_limit_id = ?", id).Delete(&model.TransferLimit{}).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Success response
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Delete success",
+		"data": map[string]string{
+			"transfer_limit_id": id,
+		},
+	})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"task-golang-batch2/lockout"
+	"task-golang-batch2/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LockoutInterface interface {
+	List(*gin.Context)
+	Unlock(*gin.Context)
+	Events(*gin.Context)
+}
+
+type lockoutImplement struct {
+	db *gorm.DB
+}
+
+func NewLockout(db *gorm.DB) LockoutInterface {
+	return &lockoutImplement{
+		db: db,
+	}
+}
+
+// Handler for "GET /admin/lockout/list", menampilkan username/IP yang sedang dikunci
+func (l *lockoutImplement) List(c *gin.Context) {
+	var throttles []model.LoginThrottle
+	if err := l.db.Where("locked_until > ?", time.Now()).
+		Order("locked_until DESC").Find(&throttles).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": throttles,
+	})
+}
+
+type lockoutUnlockPayload struct {
+	Username  string `json:"username"`
+	IPAddress string `json:"ip_address"`
+	Note      string `json:"note"`
+}
+
+// Handler for "POST /admin/lockout/unlock"
+func (l *lockoutImplement) Unlock(c *gin.Context) {
+	var payload lockoutUnlockPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	var subjects []lockout.Subject
+	if payload.Username != "" {
+		subjects = append(subjects, lockout.User(payload.Username), lockout.StepUp(payload.Username))
+	}
+	if payload.IPAddress != "" {
+		subjects = append(subjects, lockout.IP(payload.IPAddress))
+	}
+	if len(subjects) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username or ip_address is required"})
+		return
+	}
+
+	unlocked := []string{}
+	for _, subject := range subjects {
+		found, err := lockout.Unlock(l.db, subject, c.GetInt64("auth_id"), payload.Note)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if found {
+			unlocked = append(unlocked, subject.Key)
+		}
+	}
+	if len(unlocked) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Unlock success",
+		"data":    unlocked,
+	})
+}
+
+// Handler for "GET /admin/lockout/events", audit log lockout terbaru lebih dulu
+func (l *lockoutImplement) Events(c *gin.Context) {
+	limit, err := pageLimit(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cursor, err := decodeCursor(c.Query("cursor"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var events []model.AuthEvent
+	query := l.db.Order("auth_event_id DESC")
+	if username := c.Query("username"); username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if cursor != nil {
+		query = query.Where("auth_event_id < ?", cursor.ID)
+	}
+	if err := query.Limit(limit + 1).Find(&events).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page := pageInfo{Limit: limit}
+	if len(events) > limit {
+		events = events[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(pageCursor{ID: events[limit-1].AuthEventID})
+	}
+
+	c.JSON(http.StatusOK, paginated(events, page))
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"task-golang-batch2/lockout"
+	"task-golang-batch2/model"
+	"task-golang-batch2/notify"
+	"task-golang-batch2/password"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Maksimal token reset yang diterbitkan per user dalam satu jam
+const maxResetTokensPerHour = 3
+
+// Respon forgot-password selalu sama agar tidak membocorkan username mana yang terdaftar
+const forgotPasswordMessage = "If the username exists, password reset instructions have been sent"
+
+type authForgotPasswordPayload struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// Handler for "POST /auth/forgot-password"
+func (a *authImplement) ForgotPassword(c *gin.Context) {
+	var payload authForgotPasswordPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	// Rate limit per IP; setiap permintaan dihitung, terlepas dari username ada atau tidak
+	subject := lockout.ResetIP(c.ClientIP())
+	if !a.checkThrottle(c, subject) {
+		return
+	}
+	if _, err := lockout.RecordFailure(a.db, time.Now(), subject, lockout.Failure{IPAddress: c.ClientIP()}); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to record password reset request", "error", err)
+	}
+
+	// Semua pekerjaan per user (cari user, batas per user, simpan token, kirim) berjalan
+	// di background supaya waktu respon tidak berbeda antara username ada dan tidak.
+	// Context request tanpa cancel supaya request_id tetap ada di log.
+	requestCtx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(requestCtx, 30*time.Second)
+		defer cancel()
+		a.sendPasswordReset(ctx, payload.Username)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": forgotPasswordMessage})
+}
+
+// sendPasswordReset menerbitkan token reset dan mengirimkannya jika username terdaftar
+// dan belum melewati batas per jam. Kegagalan hanya dicatat di log.
+func (a *authImplement) sendPasswordReset(ctx context.Context, username string) {
+	db := a.db.WithContext(ctx)
+
+	var auth model.Auth
+	if err := db.Where("username = ?", username).First(&auth).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			slog.ErrorContext(ctx, "failed to look up user for password reset", "error", err)
+		}
+		return
+	}
+
+	// Batas per user dilewati diam-diam
+	var recent int64
+	if err := db.Model(&model.PasswordResetToken{}).
+		Where("auth_id = ? AND created_at > ?", auth.AuthID, time.Now().Add(-time.Hour)).
+		Count(&recent).Error; err != nil {
+		slog.ErrorContext(ctx, "failed to count password reset tokens", "auth_id", auth.AuthID, "error", err)
+		return
+	}
+	if recent >= maxResetTokensPerHour {
+		return
+	}
+
+	token := randomToken(32)
+	record := model.PasswordResetToken{
+		AuthID:    auth.AuthID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(a.ttl.PasswordReset),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		slog.ErrorContext(ctx, "failed to create password reset token", "auth_id", auth.AuthID, "error", err)
+		return
+	}
+
+	msg := notify.Message{
+		AuthID:   auth.AuthID,
+		Username: auth.Username,
+		Subject:  "Password reset",
+		Body: fmt.Sprintf("Use this token to reset your password: %s\nThe token expires at %s and can only be used once.",
+			token, record.ExpiresAt.Format(time.RFC3339)),
+	}
+	if err := a.notifier.Send(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "failed to send password reset", "auth_id", msg.AuthID, "error", err)
+	}
+}
+
+type authResetPasswordPayload struct {
+	Token           string `json:"token" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+	ConfirmPassword string `json:"confirmPassword" binding:"required"`
+}
+
+// Handler for "POST /auth/reset-password"
+func (a *authImplement) ResetPassword(c *gin.Context) {
+	var payload authResetPasswordPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	if payload.NewPassword != payload.ConfirmPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New passwords do not match"})
+		return
+	}
+
+	var auth model.Auth
+	invalid := false
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Kunci baris token; request paralel dengan token sama hanya satu yang lolos
+		var record model.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(payload.Token), now).
+			First(&record).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				invalid = true
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Where("auth_id = ?", record.AuthID).First(&auth).Error; err != nil {
+			return err
+		}
+
+		// Policy butuh username; jika gagal, rollback sehingga token masih bisa dipakai lagi
+		if err := a.passwords.Validate(auth.Username, payload.NewPassword); err != nil {
+			return err
+		}
+		hashedPassword, err := a.passwords.Hash(payload.NewPassword)
+		if err != nil {
+			return err
+		}
+
+		// Password baru memutus semua sesi lama, sama seperti change-password
+		if err := tx.Model(&auth).Updates(map[string]interface{}{
+			"password":      hashedPassword,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, "auth_id = ?", auth.AuthID); err != nil {
+			return err
+		}
+		if err := revokeRefreshTokens(tx, "auth_id = ?", auth.AuthID); err != nil {
+			return err
+		}
+
+		// Token reset lain milik user ini ikut hangus
+		if err := tx.Model(&model.PasswordResetToken{}).
+			Where("auth_id = ? AND used_at IS NULL", auth.AuthID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		// User yang berhasil reset tidak perlu menunggu lockout login selesai
+		if err := lockout.Reset(tx, lockout.User(auth.Username)); err != nil {
+			return err
+		}
+
+		return tx.Create(&model.AuthEvent{
+			AuthID:    &auth.AuthID,
+			Event:     model.AuthEventPasswordReset,
+			Username:  auth.Username,
+			IPAddress: c.ClientIP(),
+		}).Error
+	})
+	if err != nil {
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			respondPasswordPolicy(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	if invalid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reset token invalid or expired"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please login again"})
+}
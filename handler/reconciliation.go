@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"task-golang-batch2/ledger"
+	"task-golang-batch2/model"
+	"task-golang-batch2/reconcile"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReconciliationInterface interface {
+	Run(*gin.Context)
+	Read(*gin.Context)
+	List(*gin.Context)
+	Resolve(*gin.Context)
+}
+
+type reconciliationImplement struct {
+	db         *gorm.DB
+	autoFreeze bool
+}
+
+func NewReconciliation(db *gorm.DB, autoFreeze bool) ReconciliationInterface {
+	return &reconciliationImplement{
+		db:         db,
+		autoFreeze: autoFreeze,
+	}
+}
+
+type reconciliationRunPayload struct {
+	AutoFreeze *bool `json:"auto_freeze"`
+}
+
+// Handler for "POST /admin/reconciliation/run"
+func (r *reconciliationImplement) Run(c *gin.Context) {
+	var payload reconciliationRunPayload
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+			return
+		}
+	}
+
+	autoFreeze := r.autoFreeze
+	if payload.AutoFreeze != nil {
+		autoFreeze = *payload.AutoFreeze
+	}
+
+	run, err := reconcile.Run(r.db, model.ReconciliationTriggerAdmin, autoFreeze)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Reconciliation failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reconciliation completed",
+		"data":    run,
+	})
+}
+
+// Handler for "GET /admin/reconciliation/read/:id"
+func (r *reconciliationImplement) Read(c *gin.Context) {
+	var run model.ReconciliationRun
+
+	if err := r.db.Preload("Discrepancies").First(&run, "reconciliation_run_id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": run,
+	})
+}
+
+// Handler for "GET /admin/reconciliation/list"
+func (r *reconciliationImplement) List(c *gin.Context) {
+	limit, err := pageLimit(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cursor, err := decodeCursor(c.Query("cursor"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Run terbaru lebih dulu
+	var runs []model.ReconciliationRun
+	query := r.db.Order("reconciliation_run_id DESC")
+	if cursor != nil {
+		query = query.Where("reconciliation_run_id < ?", cursor.ID)
+	}
+	if err := query.Limit(limit + 1).Find(&runs).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page := pageInfo{Limit: limit}
+	if len(runs) > limit {
+		runs = runs[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(pageCursor{ID: runs[limit-1].ReconciliationRunID})
+	}
+
+	c.JSON(http.StatusOK, paginated(runs, page))
+}
+
+type reconciliationResolvePayload struct {
+	Rebuild  bool   `json:"rebuild"`  // Tulis ulang accounts.balance dari jurnal
+	Unfreeze bool   `json:"unfreeze"` // Buka kembali akun yang dibekukan
+	Note     string `json:"note"`
+}
+
+// Handler for "PATCH /admin/reconciliation/resolve/:id" (id adalah reconciliation_discrepancy_id)
+func (r *reconciliationImplement) Resolve(c *gin.Context) {
+	var payload reconciliationResolvePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	var discrepancy model.ReconciliationDiscrepancy
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&discrepancy, "reconciliation_discrepancy_id = ?", c.Param("id")).Error; err != nil {
+			return err
+		}
+
+		if payload.Rebuild {
+			if _, err := ledger.Rebuild(tx, discrepancy.AccountID); err != nil {
+				return err
+			}
+		}
+		if payload.Unfreeze {
+			if err := tx.Model(&model.Account{}).Where("account_id = ?", discrepancy.AccountID).
+				Update("frozen", false).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		discrepancy.ResolvedAt = &now
+		discrepancy.ResolutionNote = payload.Note
+		return tx.Model(&discrepancy).Select("resolved_at", "resolution_note").Updates(&discrepancy).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Resolve success",
+		"data":    discrepancy,
+	})
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/limits"
+	"task-golang-batch2/model"
+	"task-golang-batch2/password"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errUsernameTaken = errors.New("username already taken")
+
+// respondPasswordPolicy membalas 400 beserta daftar aturan password yang dilanggar
+func respondPasswordPolicy(c *gin.Context, err error) {
+	var policyErr *password.PolicyError
+	if errors.As(err, &policyErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Password does not meet policy",
+			"violations": policyErr.Violations,
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+type authRegisterPayload struct {
+	Name     string `json:"name" binding:"required"`
+	Username string `json:"username" binding:"required,min=3,max=64"`
+	Password string `json:"password" binding:"required"`
+	Currency string `json:"currency"`
+}
+
+// Handler for "POST /auth/register", membuat Account dan Auth nasabah dalam satu transaksi.
+// Saldo awal selalu 0; dana masuk lewat top-up.
+func (a *authImplement) Register(c *gin.Context) {
+	var payload authRegisterPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	payload.Name = strings.TrimSpace(payload.Name)
+	payload.Username = strings.TrimSpace(payload.Username)
+	if payload.Name == "" || payload.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name and username are required"})
+		return
+	}
+	if err := a.passwords.Validate(payload.Username, payload.Password); err != nil {
+		respondPasswordPolicy(c, err)
+		return
+	}
+
+	if payload.Currency == "" {
+		payload.Currency = currency.Default
+	}
+	accountCurrency, err := currency.Normalize(payload.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashed, err := a.passwords.Hash(payload.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	account := model.Account{
+		Name:     payload.Name,
+		Currency: accountCurrency,
+		Tier:     limits.DefaultTier,
+	}
+	auth := model.Auth{
+		Username: payload.Username,
+		Password: string(hashed),
+		Role:     model.RoleCustomer,
+	}
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Auth{}).Where("username = ?", auth.Username).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errUsernameTaken
+		}
+
+		if err := tx.Create(&account).Error; err != nil {
+			return err
+		}
+		auth.AccountID = account.AccountID
+		return tx.Create(&auth).Error
+	})
+	if err != nil {
+		// Registrasi paralel dengan username sama ditolak oleh constraint auth_username
+		var existing int64
+		if !errors.Is(err, errUsernameTaken) &&
+			a.db.Model(&model.Auth{}).Where("username = ?", auth.Username).Count(&existing).Error == nil && existing > 0 {
+			err = errUsernameTaken
+		}
+		if errors.Is(err, errUsernameTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Register success",
+		"data": gin.H{
+			"account":  account,
+			"username": auth.Username,
+		},
+	})
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"task-golang-batch2/model"
+	"task-golang-batch2/scheduler"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ScheduleInterface interface {
+	Create(*gin.Context)
+	Read(*gin.Context)
+	Update(*gin.Context)
+	Delete(*gin.Context)
+	List(*gin.Context)
+}
+
+type scheduleImplement struct {
+	db     *gorm.DB
+	stepUp func(c *gin.Context, amount int64) bool
+}
+
+// NewSchedule membutuhkan stepUp (middleware.StepUpCheck) karena worker menjalankan
+// jadwal tanpa 2FA; verifikasinya dilakukan saat jadwal dibuat atau diubah
+func NewSchedule(db *gorm.DB, stepUp func(c *gin.Context, amount int64) bool) ScheduleInterface {
+	return &scheduleImplement{
+		db:     db,
+		stepUp: stepUp,
+	}
+}
+
+type scheduleCreatePayload struct {
+	ToAccountID int64      `json:"to_account_id" binding:"required"`
+	Amount      int64      `json:"amount" binding:"required"`
+	Frequency   string     `json:"frequency" binding:"required"`
+	CronExpr    string     `json:"cron_expr"`
+	StartAt     time.Time  `json:"start_at" binding:"required"`
+	EndAt       *time.Time `json:"end_at"`
+	MaxRetries  *int       `json:"max_retries"`
+}
+
+// Handler for "POST /account/schedule/create"
+func (s *scheduleImplement) Create(c *gin.Context) {
+	var payload scheduleCreatePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	accountID := c.GetInt64("account_id")
+
+	// Validasi dasar sama dengan transfer biasa
+	if payload.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
+		return
+	}
+	if payload.ToAccountID == accountID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot transfer to the same account"})
+		return
+	}
+	if payload.StartAt.Before(time.Now().Add(-time.Minute)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_at must not be in the past"})
+		return
+	}
+
+	// Nominal besar wajib 2FA seperti transfer langsung
+	if !s.stepUp(c, payload.Amount) {
+		return
+	}
+
+	var recipient model.Account
+	if err := s.db.Where("account_id = ? AND is_system = ?", payload.ToAccountID, false).First(&recipient).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recipient account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := model.ScheduledTransfer{
+		AccountID:   accountID,
+		ToAccountID: payload.ToAccountID,
+		Amount:      payload.Amount,
+		Frequency:   payload.Frequency,
+		CronExpr:    payload.CronExpr,
+		StartAt:     payload.StartAt,
+		EndAt:       payload.EndAt,
+		Status:      model.ScheduleActive,
+		MaxRetries:  scheduler.DefaultMaxRetries,
+	}
+	if payload.MaxRetries != nil {
+		if *payload.MaxRetries < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_retries must not be negative"})
+			return
+		}
+		schedule.MaxRetries = *payload.MaxRetries
+	}
+
+	if err := scheduler.Validate(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Kejadian pertama adalah start_at itu sendiri (atau waktu cron pertama setelahnya)
+	next, ok := scheduler.NextOccurrence(&schedule, schedule.StartAt.Add(-time.Nanosecond))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "schedule has no upcoming run"})
+		return
+	}
+	schedule.NextRunAt = &next
+
+	if err := s.db.Create(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    schedule,
+	})
+}
+
+// findOwned mengambil jadwal milik akun yang sedang login
+func (s *scheduleImplement) findOwned(c *gin.Context, schedule *model.ScheduledTransfer) bool {
+	accountID := c.GetInt64("account_id")
+	id := c.Param("id")
+
+	if err := s.db.Where("scheduled_transfer_id = ? AND account_id = ?", id, accountID).First(schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return false
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// Handler for "GET /account/schedule/read/:id"
+func (s *scheduleImplement) Read(c *gin.Context) {
+	var schedule model.ScheduledTransfer
+	if !s.findOwned(c, &schedule) {
+		return
+	}
+
+	// Sertakan riwayat eksekusi terakhir
+	var runs []model.ScheduledTransferRun
+	if err := s.db.Where("scheduled_transfer_id = ?", schedule.ScheduledTransferID).
+		Order("executed_at DESC").Limit(20).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": schedule,
+		"runs": runs,
+	})
+}
+
+type scheduleUpdatePayload struct {
+	Amount     *int64     `json:"amount"`
+	Status     *string    `json:"status"` // active atau paused
+	EndAt      *time.Time `json:"end_at"`
+	MaxRetries *int       `json:"max_retries"`
+}
+
+// Handler for "PATCH /account/schedule/update/:id"
+func (s *scheduleImplement) Update(c *gin.Context) {
+	var payload scheduleUpdatePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	var schedule model.ScheduledTransfer
+	if !s.findOwned(c, &schedule) {
+		return
+	}
+
+	if schedule.Status == model.ScheduleCompleted || schedule.Status == model.ScheduleFailed {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Schedule is already " + schedule.Status})
+		return
+	}
+
+	if payload.Amount != nil {
+		if *payload.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
+			return
+		}
+		schedule.Amount = *payload.Amount
+	}
+	if payload.EndAt != nil {
+		schedule.EndAt = payload.EndAt
+	}
+	if payload.MaxRetries != nil {
+		if *payload.MaxRetries < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_retries must not be negative"})
+			return
+		}
+		schedule.MaxRetries = *payload.MaxRetries
+	}
+	if err := scheduler.Validate(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if payload.Status != nil {
+		switch *payload.Status {
+		case model.SchedulePaused:
+			schedule.Status = model.SchedulePaused
+		case model.ScheduleActive:
+			// Saat dilanjutkan, kejadian yang terlewat saat dijeda tidak dieksekusi
+			if schedule.Status == model.SchedulePaused {
+				schedule.RetryCount = 0
+				next, ok := scheduler.NextOccurrence(&schedule, time.Now())
+				if !ok {
+					c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "schedule has no upcoming run"})
+					return
+				}
+				schedule.NextRunAt = &next
+			}
+			schedule.Status = model.ScheduleActive
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active or paused"})
+			return
+		}
+	}
+
+	// Jadwal aktif dengan nominal besar wajib 2FA; menjeda jadwal tidak perlu
+	if schedule.Status == model.ScheduleActive && !s.stepUp(c, schedule.Amount) {
+		return
+	}
+
+	err := s.db.Model(&schedule).
+		Select("amount", "end_at", "max_retries", "status", "next_run_at", "retry_count").
+		Updates(&schedule).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    schedule,
+	})
+}
+
+// Handler for "DELETE /account/schedule/delete/:id"
+func (s *scheduleImplement) Delete(c *gin.Context) {
+	var schedule model.ScheduledTransfer
+	if !s.findOwned(c, &schedule) {
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scheduled_transfer_id = ?", schedule.ScheduledTransferID).
+			Delete(&model.ScheduledTransferRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&schedule).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Delete success",
+		"data": map[string]int64{
+			"scheduled_transfer_id": schedule.ScheduledTransferID,
+		},
+	})
+}
+
+// Handler for "GET /account/schedule/list"
+func (s *scheduleImplement) List(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var schedules []model.ScheduledTransfer
+	if err := s.db.Where("account_id = ?", accountID).Order("scheduled_transfer_id").Find(&schedules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": schedules,
+	})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"task-golang-batch2/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas panjang data perangkat yang disimpan
+const (
+	maxDeviceNameLength = 100
+	maxUserAgentLength  = 512
+)
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// startSession membuat session baru untuk login dari perangkat ini
+func startSession(tx *gorm.DB, c *gin.Context, authID int64, deviceName string) (*model.Session, error) {
+	if deviceName == "" {
+		deviceName = "Unknown device"
+	}
+
+	now := time.Now()
+	session := model.Session{
+		AuthID:     authID,
+		FamilyID:   randomToken(16),
+		DeviceName: truncate(deviceName, maxDeviceNameLength),
+		UserAgent:  truncate(c.Request.UserAgent(), maxUserAgentLength),
+		IPAddress:  c.ClientIP(),
+		LastSeenAt: now,
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// sessionForRefresh mengambil session milik family refresh token dan memperbarui
+// last seen. Family lama yang dibuat sebelum ada tabel sessions dibuatkan session.
+func sessionForRefresh(tx *gorm.DB, c *gin.Context, record *model.RefreshToken) (*model.Session, error) {
+	var session model.Session
+	err := tx.Where("family_id = ?", record.FamilyID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		session = model.Session{
+			AuthID:     record.AuthID,
+			FamilyID:   record.FamilyID,
+			DeviceName: "Unknown device",
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, errRefreshTokenInvalid
+	}
+
+	session.UserAgent = truncate(c.Request.UserAgent(), maxUserAgentLength)
+	session.IPAddress = c.ClientIP()
+	session.LastSeenAt = time.Now()
+	if err := tx.Save(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// revokeSessions mencabut session beserta semua refresh token di family-nya
+func revokeSessions(tx *gorm.DB, query string, args ...interface{}) error {
+	families := tx.Model(&model.Session{}).Select("family_id").Where(query, args...)
+	if err := revokeRefreshTokens(tx, "family_id IN (?)", families); err != nil {
+		return err
+	}
+	return tx.Model(&model.Session{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).Error
+}
+
+// Handler for "GET /auth/sessions", daftar perangkat yang masih login
+func (a *authImplement) Sessions(c *gin.Context) {
+	var sessions []model.Session
+	if err := a.db.Where("auth_id = ? AND revoked_at IS NULL", c.GetInt64("auth_id")).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	// Tandai session yang sedang dipakai request ini
+	current := c.GetInt64("session_id")
+	data := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, gin.H{
+			"session_id":   session.SessionID,
+			"device_name":  session.DeviceName,
+			"user_agent":   session.UserAgent,
+			"ip_address":   session.IPAddress,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"current":      session.SessionID == current,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+	})
+}
+
+// Handler for "DELETE /auth/sessions/:id", logout dari satu perangkat
+func (a *authImplement) RevokeSession(c *gin.Context) {
+	authID := c.GetInt64("auth_id")
+
+	var session model.Session
+	if err := a.db.Where("session_id = ? AND auth_id = ? AND revoked_at IS NULL", c.Param("id"), authID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, "session_id = ?", session.SessionID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/ledger"
 	"task-golang-batch2/model"
+	"task-golang-batch2/service"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,20 +17,23 @@ import (
 type TransactionInterface interface {
 	NewTransaction(*gin.Context)
 	TransactionList(*gin.Context)
+	Reverse(*gin.Context)
 }
 
 type transactionImplement struct {
-	db *gorm.DB
+	db       *gorm.DB
+	accounts service.AccountService
 }
 
 // NewTransaction adalah handler untuk transaksi baru
-func NewTransaction(db *gorm.DB) TransactionInterface {
+func NewTransaction(db *gorm.DB, accounts service.AccountService) TransactionInterface {
 	return &transactionImplement{
-		db: db,
+		db:       db,
+		accounts: accounts,
 	}
 }
 
-// NewTransaction membuat record transaksi baru
+// NewTransaction membuat record transaksi baru (route khusus staff)
 func (t *transactionImplement) NewTransaction(c *gin.Context) {
 	var payload model.Transaction
 
@@ -44,10 +52,13 @@ func (t *transactionImplement) NewTransaction(c *gin.Context) {
 
 	payload.AccountID = accountID.(int64)
 
-	// Set tanggal transaksi ke waktu saat ini jika tidak disediakan
-	if payload.TransactionDate.IsZero() {
-		payload.TransactionDate = time.Now()
-	}
+	// Field reversal hanya boleh diisi lewat endpoint reverse
+	payload.Type = ""
+	payload.ReversalOfID = nil
+	payload.ReversedAmount = 0
+
+	// Tanggal transaksi selalu waktu pencatatan, tidak bisa dimundurkan oleh client
+	payload.TransactionDate = time.Now()
 
 	// Buat record transaksi
 	if err := t.db.Create(&payload).Error; err != nil {
@@ -62,11 +73,8 @@ func (t *transactionImplement) NewTransaction(c *gin.Context) {
 	})
 }
 
-// TransactionList mengembalikan daftar transaksi berdasarkan `account_id`
-// TransactionList mengembalikan daftar transaksi berdasarkan `account_id` dan filter tanggal
+// TransactionList mengembalikan daftar transaksi berdasarkan `account_id` dengan filter dan cursor pagination
 func (t *transactionImplement) TransactionList(c *gin.Context) {
-	var transactions []model.Transaction
-
 	// Ambil `account_id` dari context
 	accountID, exists := c.Get("account_id")
 	if !exists {
@@ -74,55 +82,88 @@ func (t *transactionImplement) TransactionList(c *gin.Context) {
 		return
 	}
 
-	// Ambil parameter filter tanggal dari query string (jika ada)
-	startDateStr := c.DefaultQuery("start_date", "")
-	endDateStr := c.DefaultQuery("end_date", "")
+	// Ambil filter, sort dan paginasi dari query string
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := pageLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	var startDate, endDate *time.Time
+	// Siapkan query untuk mengambil transaksi berdasarkan account_id dan filter
+	query := filter.Apply(t.db.Model(&model.Transaction{}).Where("account_id = ?", accountID), accountID.(int64))
 
-	// Jika start_date diberikan, konversi string ke time.Time
-	if startDateStr != "" {
-		parsedStartDate, err := time.Parse("2006-01-02", startDateStr) // Format tanggal yang diinginkan: "YYYY-MM-DD"
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
+	transactions, page, err := findTransactionPage(filter, query, c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, errInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		startDate = &parsedStartDate
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions: " + err.Error()})
+		return
+	}
+
+	// Respon sukses dengan data transaksi
+	c.JSON(http.StatusOK, paginated(transactions, page))
+}
+
+type reversePayload struct {
+	Amount int64  `json:"amount"` // 0 atau kosong berarti refund penuh
+	Reason string `json:"reason"`
+}
+
+// Reverse membuat transaksi kompensasi (refund penuh atau sebagian) untuk transaksi `:id`
+func (t *transactionImplement) Reverse(c *gin.Context) {
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
 	}
 
-	// Jika end_date diberikan, konversi string ke time.Time
-	if endDateStr != "" {
-		parsedEndDate, err := time.Parse("2006-01-02", endDateStr) // Format tanggal yang diinginkan: "YYYY-MM-DD"
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
+	var payload reversePayload
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
 			return
 		}
-		endDate = &parsedEndDate
 	}
-
-	// Siapkan query untuk mengambil transaksi berdasarkan account_id dan filter tanggal
-	query := t.db.Where("account_id = ?", accountID)
-
-	// Jika start_date disediakan, tambahkan kondisi WHERE untuk filter tanggal mulai
-	if startDate != nil {
-		query = query.Where("transaction_date >= ?", startDate)
+	if payload.Amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ledger.ErrInvalidAmount.Error()})
+		return
 	}
 
-	// Jika end_date disediakan, tambahkan kondisi WHERE untuk filter tanggal akhir
-	if endDate != nil {
-		query = query.Where("transaction_date <= ?", endDate)
+	// Nasabah hanya boleh mengembalikan dana yang diterima akunnya, support/admin semua
+	var ownerID *int64
+	role := c.GetString("role")
+	if role != model.RoleSupport && role != model.RoleAdmin {
+		accountID := c.GetInt64("account_id")
+		ownerID = &accountID
 	}
 
-	// Ambil 10 transaksi terakhir berdasarkan account_id dan filter tanggal (jika ada)
-	if err := query.Order("transaction_date DESC").
-		Limit(10).
-		Find(&transactions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions: " + err.Error()})
+	reversal, err := t.accounts.Reverse(transactionID, payload.Amount, payload.Reason, ownerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ledger.ErrTransactionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ledger.ErrNotReversible), errors.Is(err, ledger.ErrReversalExceeds),
+			errors.Is(err, ledger.ErrInsufficientBalance), errors.Is(err, ledger.ErrAccountFrozen),
+			errors.Is(err, ledger.ErrAmountTooSmall), errors.Is(err, currency.ErrAmountOverflow):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ledger.ErrInvalidAmount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reverse transaction: " + err.Error()})
+		}
 		return
 	}
 
-	// Respon sukses dengan data transaksi
+	// Respon sukses
 	c.JSON(http.StatusOK, gin.H{
-		"data": transactions,
+		"message": "Transaction reversed successfully",
+		"data":    reversal,
 	})
 }
@@ -156,18 +156,42 @@ func (a *transactionCatImplement) List(c *gin.Context) {
 	// Prepare empty result
 	var transaction_ctgs []model.TransactionCategories
 
-	// Find and get all accounts data and put to &accounts
-	if err := a.db.Find(&transaction_ctgs).Error; err != nil {
+	limit, err := pageLimit(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	cursor, err := decodeCursor(c.Query("cursor"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Find transaction categories page by page ordered by id
+	query := a.db.Order("transaction_category_id")
+	if cursor != nil {
+		query = query.Where("transaction_category_id > ?", cursor.ID)
+	}
+	if err := query.Limit(limit + 1).Find(&transaction_ctgs).Error; err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	page := pageInfo{Limit: limit}
+	if len(transaction_ctgs) > limit {
+		transaction_ctgs = transaction_ctgs[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(pageCursor{ID: transaction_ctgs[limit-1].TransactionCatID})
+	}
+
 	// Success response
-	c.JSON(http.StatusOK, gin.H{
-		"data": transaction_ctgs,
-	})
+	c.JSON(http.StatusOK, paginated(transaction_ctgs, page))
 }
 
 func (a *transactionCatImplement) My(c *gin.Context) {
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// transactionFilter adalah filter yang dipakai bersama oleh /account/mutation dan /transaction/list
+type transactionFilter = repository.TransactionFilter
+
+func parseOptionalInt64(c *gin.Context, key string) (*int64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid " + key)
+	}
+	return &parsed, nil
+}
+
+func parseOptionalDate(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, errors.New("Invalid " + key + " format")
+	}
+	return &parsed, nil
+}
+
+// parseTransactionFilter membaca filter dari query string:
+// start_date, end_date, direction, min_amount, max_amount, category_id, counterparty_id, type, sort
+func parseTransactionFilter(c *gin.Context) (transactionFilter, error) {
+	var filter transactionFilter
+	var err error
+
+	if filter.StartDate, err = parseOptionalDate(c, "start_date"); err != nil {
+		return filter, err
+	}
+	if filter.EndDate, err = parseOptionalDate(c, "end_date"); err != nil {
+		return filter, err
+	}
+	if filter.MinAmount, err = parseOptionalInt64(c, "min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseOptionalInt64(c, "max_amount"); err != nil {
+		return filter, err
+	}
+	if filter.CategoryID, err = parseOptionalInt64(c, "category_id"); err != nil {
+		return filter, err
+	}
+	if filter.CounterpartyID, err = parseOptionalInt64(c, "counterparty_id"); err != nil {
+		return filter, err
+	}
+
+	filter.Direction = c.Query("direction")
+	if filter.Direction != "" && filter.Direction != "in" && filter.Direction != "out" {
+		return filter, errors.New("direction must be in or out")
+	}
+
+	filter.Type = c.Query("type")
+
+	filter.Sort = c.DefaultQuery("sort", repository.DefaultTransactionSort)
+	if !repository.ValidSort(filter.Sort) {
+		return filter, errors.New("sort must be one of date_desc, date_asc, amount_desc, amount_asc")
+	}
+
+	return filter, nil
+}
+
+// findPage menjalankan query dengan limit+1 untuk mengetahui apakah masih ada halaman berikutnya.
+// errInvalidCursor dikembalikan jika cursor tidak valid untuk sort yang dipilih.
+func findTransactionPage(f transactionFilter, query *gorm.DB, rawCursor string, limit int) ([]model.Transaction, pageInfo, error) {
+	cursor, err := decodeCursor(rawCursor)
+	if err != nil {
+		return nil, pageInfo{}, err
+	}
+
+	query, err = f.Paginate(query, cursor)
+	if err != nil {
+		return nil, pageInfo{}, err
+	}
+
+	var transactions []model.Transaction
+	if err := query.Limit(limit + 1).Find(&transactions).Error; err != nil {
+		return nil, pageInfo{}, err
+	}
+
+	info := pageInfo{Limit: limit}
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		info.HasMore = true
+		info.NextCursor = encodeCursor(f.CursorFor(transactions[limit-1]))
+	}
+	return transactions, info, nil
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrDraining = errors.New("server is shutting down")
+
+// Check mengembalikan error jika komponen belum siap melayani request
+type Check func(ctx context.Context) error
+
+// Registry menampung pengecekan readiness. Saat shutdown dimulai, SetDraining
+// membuat readiness gagal supaya load balancer berhenti mengirim request baru.
+type Registry struct {
+	mu       sync.Mutex
+	names    []string
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{checks: map[string]Check{}}
+}
+
+// Add mendaftarkan check dengan nama yang ditampilkan di /readyz
+func (r *Registry) Add(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.checks[name] = check
+}
+
+func (r *Registry) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Run menjalankan semua check secara paralel. Hasilnya "ok" atau pesan error per
+// nama check; ready false jika ada yang gagal atau server sedang shutdown.
+func (r *Registry) Run(ctx context.Context) (results map[string]string, ready bool) {
+	r.mu.Lock()
+	names := append([]string(nil), r.names...)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = r.checks[name]
+	}
+	r.mu.Unlock()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			errs[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	results = make(map[string]string, len(names)+1)
+	ready = true
+	for i, name := range names {
+		results[name] = "ok"
+		if errs[i] != nil {
+			results[name] = errs[i].Error()
+			ready = false
+		}
+	}
+	if r.draining.Load() {
+		results["server"] = ErrDraining.Error()
+		ready = false
+	}
+	return results, ready
+}
+
+// Heartbeat dipakai worker latar belakang untuk menandai bahwa loop-nya masih berjalan
+type Heartbeat struct {
+	running atomic.Bool
+	last    atomic.Int64 // UnixNano detak terakhir
+}
+
+// Start dipanggil saat loop worker mulai, Stop saat loop berhenti
+func (h *Heartbeat) Start() {
+	h.running.Store(true)
+	h.Beat()
+}
+
+func (h *Heartbeat) Stop() {
+	h.running.Store(false)
+}
+
+// Beat dipanggil setiap putaran loop worker
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Check gagal jika worker tidak berjalan atau tidak berdetak selama maxAge.
+// maxAge sebaiknya lebih panjang dari interval worker ditambah lama satu putaran.
+func (h *Heartbeat) Check(maxAge time.Duration) Check {
+	return func(ctx context.Context) error {
+		if !h.running.Load() {
+			return errors.New("worker is not running")
+		}
+		if age := time.Since(time.Unix(0, h.last.Load())); age > maxAge {
+			return fmt.Errorf("worker has not run for %s", age.Round(time.Second))
+		}
+		return nil
+	}
+}
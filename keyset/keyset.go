@@ -0,0 +1,281 @@
+package keyset
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Leeway untuk perbedaan jam antar server saat validasi exp/nbf
+const leeway = 30 * time.Second
+
+var (
+	ErrUnknownKey       = errors.New("unknown key id")
+	ErrNoSigningKey     = errors.New("no active signing key")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrInvalidKey       = errors.New("invalid key material")
+	ErrVerificationOnly = errors.New("key can only be used for verification")
+)
+
+// Key adalah satu kunci JWT. Kunci publik saja (tanpa private) hanya dipakai untuk
+// verifikasi, misalnya kunci lama yang sedang dirotasi keluar.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	sign   interface{} // []byte, *rsa.PrivateKey atau ed25519.PrivateKey
+	verify interface{} // []byte, *rsa.PublicKey atau ed25519.PublicKey
+}
+
+// Set menyimpan semua kunci yang diterima untuk verifikasi dan satu kunci aktif
+// untuk menandatangani token baru.
+type Set struct {
+	Issuer   string
+	Audience string
+	keys     map[string]*Key
+	order    []string
+	active   *Key
+}
+
+func New(issuer, audience string) *Set {
+	return &Set{
+		Issuer:   issuer,
+		Audience: audience,
+		keys:     map[string]*Key{},
+	}
+}
+
+func (s *Set) add(key *Key) error {
+	if key.ID == "" {
+		return errors.New("key id is required")
+	}
+	if _, exists := s.keys[key.ID]; exists {
+		return fmt.Errorf("duplicate key id %q", key.ID)
+	}
+	s.keys[key.ID] = key
+	s.order = append(s.order, key.ID)
+	return nil
+}
+
+// AddHMAC menambahkan secret HS256. Secret tidak pernah dipublikasikan lewat JWKS.
+func (s *Set) AddHMAC(kid string, secret []byte) error {
+	if len(secret) == 0 {
+		return ErrInvalidKey
+	}
+	return s.add(&Key{ID: kid, Method: jwt.SigningMethodHS256, sign: secret, verify: secret})
+}
+
+// AddPEM menambahkan kunci RS256 atau EdDSA dari PEM. PEM berisi private key
+// (PKCS#8/PKCS#1) bisa untuk signing, PEM berisi public key hanya untuk verifikasi.
+func (s *Set) AddPEM(kid, alg string, data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return ErrInvalidKey
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return ErrInvalidKey
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	key := &Key{ID: kid}
+	switch alg {
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+		switch k := parsed.(type) {
+		case *rsa.PrivateKey:
+			key.sign, key.verify = k, &k.PublicKey
+		case *rsa.PublicKey:
+			key.verify = k
+		default:
+			return fmt.Errorf("%w: RS256 needs an RSA key", ErrInvalidKey)
+		}
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+		switch k := parsed.(type) {
+		case ed25519.PrivateKey:
+			key.sign, key.verify = k, k.Public().(ed25519.PublicKey)
+		case ed25519.PublicKey:
+			key.verify = k
+		default:
+			return fmt.Errorf("%w: EdDSA needs an Ed25519 key", ErrInvalidKey)
+		}
+	default:
+		return ErrUnsupportedAlg
+	}
+	return s.add(key)
+}
+
+// LoadSpec memuat daftar kunci dari format "kid=alg:path,kid=alg:path". Untuk HS256
+// file berisi secret mentah, untuk RS256/EdDSA file berisi PEM.
+func (s *Set) LoadSpec(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, rest, ok := strings.Cut(entry, "=")
+		alg, path, ok2 := strings.Cut(rest, ":")
+		if !ok || !ok2 {
+			return fmt.Errorf("invalid key spec %q, expected kid=alg:path", entry)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", kid, err)
+		}
+
+		if alg == "HS256" {
+			err = s.AddHMAC(kid, []byte(strings.TrimSpace(string(data))))
+		} else {
+			err = s.AddPEM(kid, alg, data)
+		}
+		if err != nil {
+			return fmt.Errorf("key %q: %w", kid, err)
+		}
+	}
+	return nil
+}
+
+// SetActive memilih kunci untuk menandatangani token baru. Kunci lain tetap
+// diterima untuk verifikasi sampai dihapus dari konfigurasi.
+func (s *Set) SetActive(kid string) error {
+	key, ok := s.keys[kid]
+	if !ok {
+		return ErrUnknownKey
+	}
+	if key.sign == nil {
+		return ErrVerificationOnly
+	}
+	s.active = key
+	return nil
+}
+
+// ActiveID mengembalikan kid kunci aktif
+func (s *Set) ActiveID() string {
+	if s.active == nil {
+		return ""
+	}
+	return s.active.ID
+}
+
+// Sign menandatangani klaim dengan kunci aktif, menambahkan header kid serta klaim
+// iss, aud, iat dan nbf. Klaim exp diisi oleh pemanggil.
+func (s *Set) Sign(claims jwt.MapClaims) (string, error) {
+	if s.active == nil {
+		return "", ErrNoSigningKey
+	}
+
+	now := time.Now().Unix()
+	claims["iss"] = s.Issuer
+	claims["aud"] = s.Audience
+	if _, ok := claims["iat"]; !ok {
+		claims["iat"] = now
+	}
+	if _, ok := claims["nbf"]; !ok {
+		claims["nbf"] = now
+	}
+
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.sign)
+}
+
+// Parse memverifikasi token: kid harus dikenal, algoritma harus sama dengan
+// algoritma kunci tersebut, serta iss, aud, exp dan nbf harus valid.
+func (s *Set) Parse(tokenString string) (jwt.MapClaims, error) {
+	methods := make([]string, 0, len(s.keys))
+	for _, key := range s.keys {
+		methods = append(methods, key.Method.Alg())
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		// Cegah algorithm confusion (misal token HS256 yang ditandatangani dengan kunci publik RSA)
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, ErrUnsupportedAlg
+		}
+		return key.verify, nil
+	},
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(s.Issuer),
+		jwt.WithAudience(s.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWK adalah representasi publik satu kunci (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS mengembalikan kunci publik asimetris agar service lain bisa memverifikasi
+// token tanpa shared secret. Kunci HMAC tidak pernah ikut dipublikasikan.
+func (s *Set) JWKS() []JWK {
+	keys := []JWK{}
+	for _, kid := range s.order {
+		key := s.keys[kid]
+		switch pub := key.verify.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return keys
+}
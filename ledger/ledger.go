@@ -0,0 +1,205 @@
+package ledger
+
+import (
+	"errors"
+	"sort"
+	"task-golang-batch2/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kode akun sistem. Akun-akun ini disimpan di tabel accounts dengan is_system = true
+// dan menjadi lawan transaksi untuk uang yang masuk/keluar dari sistem.
+const (
+	SystemTopUpFunding = "SYS_TOPUP_FUNDING"
+	SystemFees         = "SYS_FEES"
+	SystemAdjustment   = "SYS_ADJUSTMENT"
+	SystemFX           = "SYS_FX"
+)
+
+var (
+	ErrUnbalanced        = errors.New("journal entry is not balanced")
+	ErrInvalidLine       = errors.New("journal line must have exactly one positive debit or credit")
+	ErrTooFewLines       = errors.New("journal entry needs at least two lines")
+	ErrSystemAccountMiss = errors.New("system account not found")
+	ErrAccountNotFound   = errors.New("account not found")
+)
+
+// Debit membuat satu kaki debit (mengurangi saldo akun nasabah).
+func Debit(accountID, amount int64) model.JournalLine {
+	return model.JournalLine{AccountID: accountID, Debit: amount}
+}
+
+// Credit membuat satu kaki kredit (menambah saldo akun nasabah).
+func Credit(accountID, amount int64) model.JournalLine {
+	return model.JournalLine{AccountID: accountID, Credit: amount}
+}
+
+// TransferLines menyusun kaki jurnal untuk transfer antar nasabah dengan mata uang sama.
+// Jika fee > 0, pengirim didebit sebesar amount + fee dan fee dikreditkan ke akun SYS_FEES.
+func TransferLines(fromAccountID, toAccountID, amount, fee, feeAccountID int64) []model.JournalLine {
+	lines := []model.JournalLine{
+		Debit(fromAccountID, amount+fee),
+		Credit(toAccountID, amount),
+	}
+	if fee > 0 {
+		lines = append(lines, Credit(feeAccountID, fee))
+	}
+	return lines
+}
+
+// systemAccountNames dipakai sebagai nama akun sistem yang dibuat otomatis
+var systemAccountNames = map[string]string{
+	SystemTopUpFunding: "System Top-Up Funding",
+	SystemFees:         "System Fees",
+	SystemAdjustment:   "System Adjustment",
+	SystemFX:           "System FX Position",
+}
+
+// SystemAccountID mengembalikan account_id dari akun sistem berdasarkan kode dan
+// mata uangnya. Akun sistem untuk mata uang baru dibuat otomatis saat pertama dipakai.
+func SystemAccountID(tx *gorm.DB, code, currency string) (int64, error) {
+	name, ok := systemAccountNames[code]
+	if !ok {
+		return 0, ErrSystemAccountMiss
+	}
+
+	find := func() (int64, error) {
+		var account model.Account
+		err := tx.Select("account_id").
+			Where("code = ? AND currency = ? AND is_system = ?", code, currency, true).
+			First(&account).Error
+		return account.AccountID, err
+	}
+
+	id, err := find()
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	// Akun sistem belum ada untuk mata uang ini; abaikan konflik jika dibuat bersamaan
+	account := model.Account{
+		Name:     name + " " + currency,
+		Currency: currency,
+		Code:     &code,
+		IsSystem: true,
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&account).Error; err != nil {
+		return 0, err
+	}
+	return find()
+}
+
+// Validate memastikan entry seimbang sebelum diposting. Jika Currency terisi,
+// keseimbangan dicek per mata uang.
+func Validate(lines []model.JournalLine) error {
+	if len(lines) < 2 {
+		return ErrTooFewLines
+	}
+
+	totals := map[string]int64{}
+	for _, line := range lines {
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit == 0) == (line.Credit == 0) {
+			return ErrInvalidLine
+		}
+		totals[line.Currency] += line.Debit - line.Credit
+	}
+
+	for _, total := range totals {
+		if total != 0 {
+			return ErrUnbalanced
+		}
+	}
+	return nil
+}
+
+// Post menyimpan journal entry beserta kaki-kakinya lalu memperbarui proyeksi
+// accounts.balance. Harus dipanggil di dalam transaksi database milik pemanggil.
+func Post(tx *gorm.DB, entry *model.JournalEntry) error {
+	// Isi mata uang setiap kaki dari akunnya
+	accountIDs := make([]int64, 0, len(entry.Lines))
+	for _, line := range entry.Lines {
+		accountIDs = append(accountIDs, line.AccountID)
+	}
+	var accounts []model.Account
+	if err := tx.Select("account_id", "currency").Where("account_id IN ?", accountIDs).Find(&accounts).Error; err != nil {
+		return err
+	}
+	currencies := make(map[int64]string, len(accounts))
+	for _, account := range accounts {
+		currencies[account.AccountID] = account.Currency
+	}
+	for i := range entry.Lines {
+		currency, ok := currencies[entry.Lines[i].AccountID]
+		if !ok {
+			return ErrAccountNotFound
+		}
+		entry.Lines[i].Currency = currency
+	}
+
+	if err := Validate(entry.Lines); err != nil {
+		return err
+	}
+
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+
+	// Gabungkan perubahan per akun dan update dengan urutan account_id yang stabil
+	deltas := map[int64]int64{}
+	for _, line := range entry.Lines {
+		deltas[line.AccountID] += line.Credit - line.Debit
+	}
+
+	accountIDs = accountIDs[:0]
+	for id := range deltas {
+		accountIDs = append(accountIDs, id)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	for _, id := range accountIDs {
+		if deltas[id] == 0 {
+			continue
+		}
+		if err := tx.Model(&model.Account{}).Where("account_id = ?", id).
+			Update("balance", gorm.Expr("balance + ?", deltas[id])).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Balance menghitung ulang saldo akun langsung dari jurnal (kredit - debit).
+func Balance(tx *gorm.DB, accountID int64) (int64, error) {
+	var balance int64
+	err := tx.Model(&model.JournalLine{}).
+		Select("COALESCE(SUM(credit - debit), 0)").
+		Where("account_id = ?", accountID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// Rebuild menulis ulang accounts.balance dari hasil perhitungan jurnal. tx harus
+// transaksi: akun dikunci dulu supaya posting yang berjalan bersamaan tidak
+// tertimpa saldo lama.
+func Rebuild(tx *gorm.DB, accountID int64) (int64, error) {
+	if _, err := LockAccounts(tx, accountID); err != nil {
+		return 0, err
+	}
+
+	balance, err := Balance(tx, accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Model(&model.Account{}).Where("account_id = ?", accountID).
+		Update("balance", balance).Error; err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
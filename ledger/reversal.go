@@ -0,0 +1,21 @@
+package ledger
+
+import (
+	"errors"
+	"task-golang-batch2/model"
+)
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNotReversible       = errors.New("transaction cannot be reversed")
+	ErrReversalExceeds     = errors.New("reversal amount exceeds the remaining reversible amount")
+)
+
+// CreditedAccountID mengembalikan akun yang menerima dana dari transaksi asli.
+// Transaksi top-up lama hanya mengisi account_id tanpa to_account_id.
+func CreditedAccountID(original *model.Transaction) int64 {
+	if original.ToAccountID != nil {
+		return *original.ToAccountID
+	}
+	return original.AccountID
+}
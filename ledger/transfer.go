@@ -0,0 +1,55 @@
+package ledger
+
+import (
+	"errors"
+	"sort"
+	"task-golang-batch2/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSelfTransfer        = errors.New("cannot transfer to the same account")
+	ErrSenderNotFound      = errors.New("sender account not found")
+	ErrRecipientNotFound   = errors.New("recipient account not found")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrInvalidAmount       = errors.New("amount must be greater than zero")
+	ErrAmountTooSmall      = errors.New("amount is too small to convert")
+	ErrAccountFrozen       = errors.New("account is frozen pending review")
+)
+
+// LockAccounts mengunci baris akun dengan SELECT ... FOR UPDATE. Urutan penguncian
+// selalu berdasarkan account_id menaik sehingga dua transfer yang berlawanan arah
+// tidak saling deadlock. Akun yang tidak ditemukan tidak ada di map hasil.
+func LockAccounts(tx *gorm.DB, accountIDs ...int64) (map[int64]model.Account, error) {
+	ids := append([]int64(nil), accountIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var accounts []model.Account
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id IN ?", ids).
+		Order("account_id").
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[int64]model.Account, len(accounts))
+	for _, account := range accounts {
+		locked[account.AccountID] = account
+	}
+	return locked, nil
+}
+
+// FXLines menyusun kaki jurnal untuk perpindahan dana beda mata uang melalui akun
+// sistem SYS_FX per mata uang, sehingga setiap mata uang tetap seimbang: debit
+// pengirim / kredit FX asal, lalu debit FX tujuan / kredit penerima.
+func FXLines(fromAccountID, toAccountID, amount, counterAmount, fxFromID, fxToID int64) []model.JournalLine {
+	return []model.JournalLine{
+		Debit(fromAccountID, amount),
+		Credit(fxFromID, amount),
+		Debit(fxToID, counterAmount),
+		Credit(toAccountID, counterAmount),
+	}
+}
@@ -0,0 +1,221 @@
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTier dipakai untuk akun tanpa tier
+const DefaultTier = "basic"
+
+// Nama limit yang dikembalikan ke klien
+const (
+	LimitPerTransaction = "per_transaction"
+	LimitDaily          = "daily"
+	LimitMonthly        = "monthly"
+)
+
+// Jendela rolling untuk limit harian dan bulanan
+const (
+	dailyWindow   = 24 * time.Hour
+	monthlyWindow = 30 * 24 * time.Hour
+)
+
+// ErrCurrencyNotConfigured berarti tier akun punya limit, tetapi tidak untuk mata
+// uang akun. Transfer ditolak daripada memakai nominal dari mata uang lain.
+var ErrCurrencyNotConfigured = errors.New("transfer limits are not configured for this tier and currency")
+
+// Policy adalah limit efektif untuk satu akun; nil berarti tidak dibatasi
+type Policy struct {
+	PerTransaction *int64 `json:"per_transaction"`
+	Daily          *int64 `json:"daily"`
+	Monthly        *int64 `json:"monthly"`
+}
+
+// LimitError menjelaskan limit mana yang terlampaui dan kapan cukup kuota tersedia lagi
+type LimitError struct {
+	Limit     string     `json:"limit"`
+	Max       int64      `json:"max"`
+	Used      int64      `json:"used"`
+	Requested int64      `json:"requested"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s transfer limit exceeded", e.Limit)
+}
+
+// Resolve mengambil limit tier akun (semua mata uang) beserta override-nya lalu
+// menggabungkannya dengan FromRows.
+func Resolve(tx *gorm.DB, account model.Account) (Policy, error) {
+	tier := account.Tier
+	if tier == "" {
+		tier = DefaultTier
+	}
+
+	var rows []model.TransferLimit
+	if err := tx.Where("tier = ? OR account_id = ?", tier, account.AccountID).Find(&rows).Error; err != nil {
+		return Policy{}, err
+	}
+
+	return FromRows(rows, account.Currency)
+}
+
+// FromRows menggabungkan baris limit tier untuk currency dengan baris override akun.
+// Field override yang terisi menggantikan nilai tier. Tier tanpa baris sama sekali
+// tidak dibatasi; tier yang hanya punya baris untuk mata uang lain menghasilkan
+// ErrCurrencyNotConfigured.
+func FromRows(rows []model.TransferLimit, currency string) (Policy, error) {
+	var policy Policy
+	var override *model.TransferLimit
+	tierRows, found := 0, false
+	for i := range rows {
+		if rows[i].AccountID != nil {
+			override = &rows[i]
+			continue
+		}
+		tierRows++
+		if rows[i].Currency == nil || *rows[i].Currency != currency {
+			continue
+		}
+		found = true
+		policy = Policy{
+			PerTransaction: rows[i].PerTransaction,
+			Daily:          rows[i].Daily,
+			Monthly:        rows[i].Monthly,
+		}
+	}
+	if tierRows > 0 && !found {
+		return Policy{}, ErrCurrencyNotConfigured
+	}
+
+	if override != nil {
+		if override.PerTransaction != nil {
+			policy.PerTransaction = override.PerTransaction
+		}
+		if override.Daily != nil {
+			policy.Daily = override.Daily
+		}
+		if override.Monthly != nil {
+			policy.Monthly = override.Monthly
+		}
+	}
+	return policy, nil
+}
+
+// Outgoing adalah transfer keluar di dalam jendela rolling, dikurangi yang sudah di-refund
+type Outgoing struct {
+	Amount          int64
+	TransactionDate time.Time
+}
+
+// OutgoingSince mengambil transfer keluar akun setelah since, urut dari yang terlama.
+// Hanya baris bertipe transfer (ditulis oleh service transfer) yang dihitung; baris
+// tanpa tipe bisa berasal dari client sehingga tidak boleh memakan limit akun lain.
+func OutgoingSince(tx *gorm.DB, accountID int64, since time.Time) ([]Outgoing, error) {
+	var rows []Outgoing
+	err := tx.Model(&model.Transaction{}).
+		Select("amount - reversed_amount AS amount, transaction_date").
+		Where("from_account_id = ? AND transaction_date > ?", accountID, since).
+		Where("type = ?", model.TransactionTypeTransfer).
+		Order("transaction_date").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Usage mengembalikan total transfer keluar dalam jendela harian dan bulanan
+func Usage(tx *gorm.DB, accountID int64, now time.Time) (daily, monthly int64, err error) {
+	rows, err := OutgoingSince(tx, accountID, Since(now))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, row := range rows {
+		monthly += row.Amount
+		if row.TransactionDate.After(now.Add(-dailyWindow)) {
+			daily += row.Amount
+		}
+	}
+	return daily, monthly, nil
+}
+
+// Since adalah batas awal transfer keluar yang dibutuhkan Evaluate pada waktu now
+func Since(now time.Time) time.Time {
+	return now.Add(-monthlyWindow)
+}
+
+// Evaluate adalah aturan limit tanpa akses database: rows adalah transfer keluar
+// sejak Since(now), diurutkan dari yang terlama.
+func Evaluate(policy Policy, rows []Outgoing, amount int64, now time.Time) error {
+	if policy.PerTransaction != nil && amount > *policy.PerTransaction {
+		return &LimitError{
+			Limit:     LimitPerTransaction,
+			Max:       *policy.PerTransaction,
+			Requested: amount,
+		}
+	}
+
+	if policy.Daily != nil {
+		if err := checkWindow(LimitDaily, *policy.Daily, dailyWindow, rows, amount, now); err != nil {
+			return err
+		}
+	}
+	if policy.Monthly != nil {
+		if err := checkWindow(LimitMonthly, *policy.Monthly, monthlyWindow, rows, amount, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWindow menghitung pemakaian dalam jendela rolling. Jika terlampaui, resets_at
+// adalah saat transaksi tertua yang cukup sudah keluar dari jendela.
+func checkWindow(name string, max int64, window time.Duration, rows []Outgoing, amount int64, now time.Time) error {
+	since := now.Add(-window)
+
+	var used int64
+	var inWindow []Outgoing
+	for _, row := range rows {
+		if row.TransactionDate.After(since) {
+			used += row.Amount
+			inWindow = append(inWindow, row)
+		}
+	}
+
+	if used+amount <= max {
+		return nil
+	}
+
+	limitErr := &LimitError{
+		Limit:     name,
+		Max:       max,
+		Used:      used,
+		Requested: amount,
+	}
+
+	// Jika amount sendiri melebihi max, kuota tidak akan pernah cukup
+	if amount <= max {
+		remaining := used
+		for _, row := range inWindow {
+			remaining -= row.Amount
+			if remaining+amount <= max {
+				resetsAt := row.TransactionDate.Add(window)
+				limitErr.ResetsAt = &resetsAt
+				break
+			}
+		}
+	}
+
+	return limitErr
+}
+
+// AsLimitError mengembalikan *LimitError jika err berasal dari pengecekan limit
+func AsLimitError(err error) (*LimitError, bool) {
+	var limitErr *LimitError
+	ok := errors.As(err, &limitErr)
+	return limitErr, ok
+}
@@ -0,0 +1,281 @@
+package lockout
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Policy mengatur backoff dan lockout untuk satu jenis kunci
+type Policy struct {
+	MaxFailures        int           // Jumlah gagal sebelum dikunci
+	FailureWindow      time.Duration // Hitungan gagal di-reset jika tidak ada gagal selama ini
+	BaseDelay          time.Duration // Jeda setelah gagal pertama, dua kali lipat tiap gagal berikutnya
+	MaxDelay           time.Duration
+	LockoutDuration    time.Duration // Durasi lockout pertama, dua kali lipat tiap lockout berikutnya
+	MaxLockoutDuration time.Duration
+}
+
+// UserPolicy berlaku per username, IPPolicy per alamat IP. Batas IP lebih longgar
+// karena satu IP bisa dipakai banyak user (NAT), dan tanpa backoff per percobaan.
+var (
+	UserPolicy = Policy{
+		MaxFailures:        5,
+		FailureWindow:      15 * time.Minute,
+		BaseDelay:          time.Second,
+		MaxDelay:           30 * time.Second,
+		LockoutDuration:    15 * time.Minute,
+		MaxLockoutDuration: 24 * time.Hour,
+	}
+	IPPolicy = Policy{
+		MaxFailures:        50,
+		FailureWindow:      15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+		MaxLockoutDuration: 24 * time.Hour,
+	}
+	// ResetPolicy membatasi permintaan lupa password per IP (setiap permintaan dihitung)
+	ResetPolicy = Policy{
+		MaxFailures:        10,
+		FailureWindow:      time.Hour,
+		LockoutDuration:    time.Hour,
+		MaxLockoutDuration: 24 * time.Hour,
+	}
+)
+
+// Subject adalah kunci throttle beserta policy-nya
+type Subject struct {
+	Key    string
+	Policy Policy
+}
+
+// User membuat subject untuk username; username yang tidak terdaftar tetap dihitung
+// agar respon tidak membocorkan username mana yang ada.
+func User(username string) Subject {
+	return Subject{Key: "user:" + strings.ToLower(username), Policy: UserPolicy}
+}
+
+// IP membuat subject untuk alamat IP klien
+func IP(address string) Subject {
+	return Subject{Key: "ip:" + address, Policy: IPPolicy}
+}
+
+// ResetIP membuat subject untuk rate limit lupa password per alamat IP
+func ResetIP(address string) Subject {
+	return Subject{Key: "reset-ip:" + address, Policy: ResetPolicy}
+}
+
+// StepUp membuat subject untuk verifikasi 2FA tambahan (step-up) per username,
+// terpisah dari hitungan login supaya kode salah saat transfer tetap dibatasi
+func StepUp(username string) Subject {
+	return Subject{Key: "stepup:" + strings.ToLower(username), Policy: UserPolicy}
+}
+
+// delay menghitung jeda backoff setelah sejumlah kegagalan
+func (p Policy) delay(failures int) time.Duration {
+	if p.BaseDelay <= 0 || failures <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// lockoutDuration menghitung durasi lockout ke-n (dimulai dari 1)
+func (p Policy) lockoutDuration(lockouts int) time.Duration {
+	duration := p.LockoutDuration
+	for i := 1; i < lockouts && duration < p.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+	if duration > p.MaxLockoutDuration {
+		duration = p.MaxLockoutDuration
+	}
+	return duration
+}
+
+// wait menghitung berapa lama subject harus menunggu berdasarkan row throttle-nya.
+// Percobaan yang sudah dipesan tapi belum selesai ikut dihitung, sehingga jika
+// batas sudah habis oleh percobaan yang sedang berjalan klien diminta mencoba lagi.
+func (p Policy) wait(row model.LoginThrottle, now time.Time) time.Duration {
+	if row.LockedUntil != nil && row.LockedUntil.After(now) {
+		return row.LockedUntil.Sub(now)
+	}
+	if now.Sub(row.LastFailedAt) >= p.FailureWindow {
+		return 0
+	}
+	if row.Failures >= p.MaxFailures {
+		return time.Second
+	}
+	return row.LastFailedAt.Add(p.delay(row.Failures)).Sub(now)
+}
+
+// errWait membatalkan transaksi Reserve tanpa menyimpan apa pun
+var errWait = errors.New("lockout: must wait")
+
+// Reserve memeriksa semua subject dan, jika tidak ada yang harus menunggu, langsung
+// menghitung percobaan ini sebagai gagal sebelum password diverifikasi. Row dikunci
+// (SELECT ... FOR UPDATE) selama pemeriksaan sehingga burst paralel tidak bisa
+// melewati batas. Mengembalikan lama menunggu; 0 berarti percobaan boleh dilakukan
+// dan hasilnya harus dilaporkan lewat RecordFailure, Release atau Reset.
+func Reserve(db *gorm.DB, now time.Time, subjects ...Subject) (time.Duration, error) {
+	// Urutan kunci tetap supaya dua request tidak saling deadlock
+	sorted := append([]Subject(nil), subjects...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	var wait time.Duration
+	err := db.Transaction(func(tx *gorm.DB) error {
+		rows := make([]model.LoginThrottle, len(sorted))
+		for i, subject := range sorted {
+			if err := tx.Exec(`
+				INSERT INTO login_throttles (throttle_key, failures, lockouts, last_failed_at)
+				VALUES (?, 0, 0, ?)
+				ON CONFLICT (throttle_key) DO NOTHING`, subject.Key, now).Error; err != nil {
+				return err
+			}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("throttle_key = ?", subject.Key).First(&rows[i]).Error; err != nil {
+				return err
+			}
+			if w := subject.Policy.wait(rows[i], now); w > wait {
+				wait = w
+			}
+		}
+		if wait > 0 {
+			return errWait
+		}
+
+		// Hitungan di-reset jika gagal terakhir sudah di luar window
+		for i, subject := range sorted {
+			failures := rows[i].Failures + 1
+			if now.Sub(rows[i].LastFailedAt) >= subject.Policy.FailureWindow {
+				failures = 1
+			}
+			if err := tx.Model(&model.LoginThrottle{}).Where("throttle_key = ?", subject.Key).
+				Updates(map[string]interface{}{"failures": failures, "last_failed_at": now}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errWait) {
+		return wait, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// Failure berisi konteks percobaan gagal untuk catatan audit
+type Failure struct {
+	AuthID    *int64
+	Username  string
+	IPAddress string
+}
+
+// RecordFailure menandai percobaan yang sudah dipesan lewat Reserve sebagai gagal dan
+// mengunci subject jika batas tercapai. Mengembalikan true jika percobaan ini memicu lockout.
+func RecordFailure(db *gorm.DB, now time.Time, subject Subject, failure Failure) (bool, error) {
+	locked := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var row model.LoginThrottle
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("throttle_key = ?", subject.Key).First(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if row.Failures < subject.Policy.MaxFailures || (row.LockedUntil != nil && row.LockedUntil.After(now)) {
+			return nil
+		}
+
+		row.Lockouts++
+		until := now.Add(subject.Policy.lockoutDuration(row.Lockouts))
+		if err := tx.Model(&model.LoginThrottle{}).Where("throttle_key = ?", row.ThrottleKey).
+			Updates(map[string]interface{}{"lockouts": row.Lockouts, "locked_until": until}).Error; err != nil {
+			return err
+		}
+
+		locked = true
+		return tx.Create(&model.AuthEvent{
+			AuthID:    failure.AuthID,
+			Event:     model.AuthEventLocked,
+			Username:  failure.Username,
+			IPAddress: failure.IPAddress,
+			Detail:    subject.Key + " locked until " + until.Format(time.RFC3339),
+		}).Error
+	})
+	return locked, err
+}
+
+// Release membatalkan satu percobaan yang sudah dipesan lewat Reserve, misalnya
+// karena password benar tapi login belum selesai (2FA) atau verifikasi error
+func Release(db *gorm.DB, subject Subject) error {
+	// Row yang tidak menyimpan apa pun lagi dihapus supaya tabel tidak terus bertambah
+	if err := db.Where("throttle_key = ? AND failures <= 1 AND lockouts = 0 AND locked_until IS NULL", subject.Key).
+		Delete(&model.LoginThrottle{}).Error; err != nil {
+		return err
+	}
+	return db.Model(&model.LoginThrottle{}).Where("throttle_key = ? AND failures > 0", subject.Key).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+// Reset menghapus hitungan gagal setelah login berhasil. Jumlah lockout sebelumnya
+// tetap disimpan supaya lockout berikutnya tetap bertambah panjang.
+func Reset(db *gorm.DB, subject Subject) error {
+	if err := db.Where("throttle_key = ? AND lockouts = 0", subject.Key).
+		Delete(&model.LoginThrottle{}).Error; err != nil {
+		return err
+	}
+	return db.Model(&model.LoginThrottle{}).Where("throttle_key = ?", subject.Key).
+		Updates(map[string]interface{}{"failures": 0, "locked_until": nil}).Error
+}
+
+// Unlock membuka lockout secara manual oleh admin dan mencatatnya di audit.
+// Mengembalikan false jika subject tidak sedang tercatat.
+func Unlock(db *gorm.DB, subject Subject, actorID int64, detail string) (bool, error) {
+	found := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("throttle_key = ?", subject.Key).Delete(&model.LoginThrottle{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		found = true
+		event := model.AuthEvent{
+			Event:   model.AuthEventUnlocked,
+			ActorID: &actorID,
+			Detail:  subject.Key,
+		}
+		if detail != "" {
+			event.Detail += ": " + detail
+		}
+		scope, value, _ := strings.Cut(subject.Key, ":")
+		if scope == "user" {
+			event.Username = value
+			var auth model.Auth
+			if err := tx.Select("auth_id").Where("LOWER(username) = ?", value).First(&auth).Error; err == nil {
+				event.AuthID = &auth.AuthID
+			}
+		} else {
+			event.IPAddress = value
+		}
+		return tx.Create(&event).Error
+	})
+	return found, err
+}
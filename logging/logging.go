@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// request adalah data korelasi satu request. Disimpan sebagai pointer di context
+// supaya account_id yang baru diketahui setelah autentikasi ikut tercatat di log
+// akhir request.
+type request struct {
+	id        string
+	accountID atomic.Int64
+}
+
+type requestKey struct{}
+
+// WithRequestID menandai ctx dengan request ID; semua log yang memakai ctx ini
+// (slog.InfoContext dan sejenisnya) membawa request_id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{id: id})
+}
+
+// RequestID mengembalikan request ID dari ctx, kosong jika tidak ada
+func RequestID(ctx context.Context) string {
+	if r, ok := ctx.Value(requestKey{}).(*request); ok {
+		return r.id
+	}
+	return ""
+}
+
+// SetAccountID mencatat akun yang terautentikasi pada request di ctx
+func SetAccountID(ctx context.Context, accountID int64) {
+	if r, ok := ctx.Value(requestKey{}).(*request); ok {
+		r.accountID.Store(accountID)
+	}
+}
+
+// Handler menambahkan request_id dan account_id dari context ke setiap log
+type Handler struct {
+	next slog.Handler
+}
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if r, ok := ctx.Value(requestKey{}).(*request); ok {
+		record = record.Clone()
+		record.AddAttrs(slog.String("request_id", r.id))
+		if accountID := r.accountID.Load(); accountID != 0 {
+			record.AddAttrs(slog.Int64("account_id", accountID))
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Redacted menggantikan nilai sensitif di log
+const Redacted = "********"
+
+// sensitive adalah potongan nama key yang nilainya tidak boleh masuk log,
+// misal password, new_password, refresh_token, X-API-Key, signing_key, dsn
+var sensitive = []string{"password", "token", "secret", "authorization", "api_key", "apikey", "api-key", "signing_key", "dsn", "otp"}
+
+// IsSensitive melaporkan apakah nilai dengan nama key ini harus disamarkan
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitive {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceAttr dipasang di slog.HandlerOptions untuk menyamarkan attribute sensitif
+func ReplaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) && attr.Value.Kind() != slog.KindGroup {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
+
+// RedactQuery mengembalikan query string dengan nilai parameter sensitif disamarkan
+func RedactQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		for _, value := range query[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(key))
+			b.WriteByte('=')
+			if IsSensitive(key) {
+				b.WriteString(Redacted)
+			} else {
+				b.WriteString(url.QueryEscape(value))
+			}
+		}
+	}
+	return b.String()
+}
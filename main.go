@@ -1,10 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"io/fs"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"task-golang-batch2/config"
 	"task-golang-batch2/handler"
+	"task-golang-batch2/health"
+	"task-golang-batch2/keyset"
+	"task-golang-batch2/lockout"
+	"task-golang-batch2/logging"
 	"task-golang-batch2/middleware"
+	"task-golang-batch2/migrations"
+	"task-golang-batch2/model"
+	"task-golang-batch2/notify"
+	"task-golang-batch2/password"
+	"task-golang-batch2/reconcile"
+	"task-golang-batch2/repository"
+	"task-golang-batch2/scheduler"
+	"task-golang-batch2/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -14,31 +38,95 @@ import (
 )
 
 func main() {
-	// Env
-	err := godotenv.Load()
+	// .env opsional; variabel yang sudah ada di environment tidak ditimpa
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Fatal("Error loading .env file: ", err)
+	}
+
+	// Config: default < file (--config / CONFIG_FILE) < env < flag
+	cfg, options, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatal("Error loading .env file", err)
+		log.Fatal("invalid configuration:\n", err)
+	}
+	// --print-config tetap bisa dipakai untuk memeriksa konfigurasi yang belum valid
+	if options.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Log JSON ke stdout; log.Printf dari library ikut lewat logger ini
+	if err := cfg.Log.Validate(); err != nil {
+		log.Fatal("invalid configuration:\n", err)
+	}
+	logger := NewLogger(cfg.Log)
+	slog.SetDefault(logger)
+	gormLogger := logging.NewGorm(logger, cfg.Log.SlowQuery)
+
+	// Subcommand "migrate" hanya butuh konfigurasi database
+	if len(options.Args) > 0 {
+		if options.Args[0] != "migrate" {
+			log.Fatalf("unknown command %q, expected migrate", options.Args[0])
+		}
+		if err := cfg.Database.Validate(); err != nil {
+			log.Fatal("invalid configuration:\n", err)
+		}
+		if err := RunMigrate(NewDatabase(cfg.Database, gormLogger), options.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("invalid configuration:\n", err)
 	}
 
 	// Database
-	db := NewDatabase()
+	db := NewDatabase(cfg.Database, gormLogger)
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Fatal("failed to get DB from GORM:", err)
 	}
 	defer sqlDB.Close()
 
-	// secret-key
-	signingKey := os.Getenv("SIGNING_KEY")
-	if signingKey == "" {
-		log.Fatal("SIGNING_KEY not set in environment")
+	// Skema harus mutakhir sebelum melayani request
+	if cfg.Database.AutoMigrate {
+		applied, err := migrations.Up(db, 0)
+		for _, migration := range applied {
+			slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
+	if err := migrations.Check(db); err != nil {
+		log.Fatal("database schema check failed: ", err)
+	}
+
+	// Keyset JWT untuk signing dan verifikasi token
+	keys := NewKeyset(cfg.JWT)
+
+	authMiddleware := middleware.AuthMiddleware(keys, db)
+
+	// Batas login gagal sebelum username / IP dikunci
+	lockout.UserPolicy.MaxFailures = cfg.Limits.LoginMaxFailures
+	lockout.IPPolicy.MaxFailures = cfg.Limits.LoginIPMaxFailures
 
-	r := gin.Default()
+	// Logger bawaan gin diganti log per request dengan request_id dan account_id
+	r := gin.New()
+	// Tanpa daftar ini gin mempercayai X-Forwarded-For dari siapa pun, sehingga IP
+	// untuk throttle login bisa dipalsukan
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("invalid server.trusted_proxies: ", err)
+	}
+	r.Use(middleware.RequestID(), middleware.RequestLogger(logger), middleware.Recovery(logger))
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173", "http://localhost:3000"}, // Ganti dengan domain yang diperbolehkan
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", middleware.IdempotencyHeader, middleware.StepUpHeader, middleware.APIKeyHeader, middleware.RequestIDHeader},
 		AllowCredentials: true,
 	})
 
@@ -48,55 +136,305 @@ func main() {
 		ctx.Next()
 	})
 
+	// Probe untuk orchestrator: /healthz (liveness) dan /readyz (readiness)
+	readiness := health.NewRegistry()
+	readiness.Add("database", func(ctx context.Context) error {
+		return sqlDB.PingContext(ctx)
+	})
+	readiness.Add("migrations", func(ctx context.Context) error {
+		return migrations.Check(db.WithContext(ctx))
+	})
+	healthHandler := handler.NewHealth(readiness)
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
+
+	// Aturan bisnis transfer, top-up, mutasi dan login ada di service; handler hanya adapter HTTP
+	store := repository.NewGorm(db)
+	passwords := NewPasswordManager(cfg.Password)
+	accountService := service.NewAccountService(store, time.Now)
+	authService := service.NewAuthService(store, passwords)
+
 	// grouping route with /auth
-	authHandler := handler.NewAuth(db, []byte(signingKey))
+	authHandler := handler.NewAuth(db, keys, NewNotifier(cfg.Notify), passwords, authService, handler.TokenTTL{
+		Access:        cfg.Tokens.AccessTTL,
+		Refresh:       cfg.Tokens.RefreshTTL,
+		Challenge:     cfg.Tokens.ChallengeTTL,
+		PasswordReset: cfg.Tokens.PasswordResetTTL,
+	})
+	// Kunci publik JWT untuk service lain
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
 	authRoute := r.Group("/auth")
 	authRoute.POST("/login", authHandler.Login)
-	authRoute.POST("/upsert", authHandler.Upsert)
+	if cfg.Features.Registration {
+		authRoute.POST("/register", authHandler.Register)
+	}
 	// Tambahkan route baru untuk /change-password dengan menggunakan middleware AuthMiddleware
-	authRoute.POST("/change-password", middleware.AuthMiddleware(signingKey), authHandler.ChangePassword)
-
-	// grouping route with /account
-	accountHandler := handler.NewAccount(db)
-	accountRoutes := r.Group("/account")
-	accountRoutes.POST("/create", accountHandler.Create)
-	accountRoutes.GET("/read/:id", accountHandler.Read)
-	accountRoutes.PATCH("/update/:id", accountHandler.Update)
-	accountRoutes.DELETE("/delete/:id", accountHandler.Delete)
-	accountRoutes.GET("/list", accountHandler.List)
-	accountRoutes.POST("/topup", accountHandler.TopUp)
-	accountRoutes.POST("/transfer", middleware.AuthMiddleware(signingKey), accountHandler.Transfer)
-	accountRoutes.GET("/mutation", middleware.AuthMiddleware(signingKey), accountHandler.Mutation)
-	accountRoutes.GET("/balance", middleware.AuthMiddleware(signingKey), accountHandler.Balance)
-
-	accountRoutes.GET("/my", middleware.AuthMiddleware(signingKey), accountHandler.My)
+	authRoute.POST("/change-password", authMiddleware, authHandler.ChangePassword)
+	authRoute.POST("/refresh", authHandler.Refresh)
+	authRoute.POST("/logout", authMiddleware, authHandler.Logout)
+	authRoute.POST("/login/2fa", authHandler.LoginTwoFactor)
+	authRoute.POST("/2fa/enroll", authMiddleware, authHandler.EnrollTwoFactor)
+	authRoute.POST("/2fa/verify", authMiddleware, authHandler.VerifyTwoFactor)
+	authRoute.POST("/2fa/disable", authMiddleware, authHandler.DisableTwoFactor)
+	authRoute.POST("/forgot-password", authHandler.ForgotPassword)
+	authRoute.POST("/reset-password", authHandler.ResetPassword)
+	authRoute.GET("/sessions", authMiddleware, authHandler.Sessions)
+	authRoute.DELETE("/sessions/:id", authMiddleware, authHandler.RevokeSession)
+
+	// Role yang boleh mengelola data nasabah
+	staff := middleware.RequireRole(model.RoleSupport, model.RoleAdmin)
+	admin := middleware.RequireRole(model.RoleAdmin)
+
+	// Upsert menimpa username/password berdasarkan account_id, hanya untuk admin
+	authRoute.POST("/upsert", authMiddleware, admin, authHandler.Upsert)
+
+	// API key (service principal) hanya bisa memakai route dengan RequireScope
+	user := middleware.RequireUser()
+
+	// grouping route with /account, semua route wajib login atau memakai API key
+	accountHandler := handler.NewAccount(db, accountService)
+	accountRoutes := r.Group("/account", middleware.ServiceAuthMiddleware(keys, db))
+	accountRoutes.POST("/create", middleware.RequireScope(model.ScopeAccountsWrite), staff, accountHandler.Create)
+	accountRoutes.GET("/read/:id", middleware.RequireScope(model.ScopeAccountsRead), middleware.RequireOwnAccount("id", model.RoleSupport, model.RoleAdmin), accountHandler.Read)
+	accountRoutes.PATCH("/update/:id", admin, accountHandler.Update)
+	accountRoutes.DELETE("/delete/:id", admin, accountHandler.Delete)
+	accountRoutes.GET("/list", middleware.RequireScope(model.ScopeAccountsRead), staff, accountHandler.List)
+	accountRoutes.POST("/topup", middleware.RequireScope(model.ScopeAccountsWrite), staff, middleware.Idempotency(db), accountHandler.TopUp)
+	accountRoutes.POST("/transfer", middleware.RequireScope(model.ScopeTransfersWrite), middleware.Idempotency(db), middleware.StepUp(db, cfg.Limits.StepUpAmount), accountHandler.Transfer)
+	accountRoutes.GET("/mutation", user, accountHandler.Mutation)
+	accountRoutes.GET("/balance", user, accountHandler.Balance)
+	accountRoutes.GET("/statement", user, accountHandler.Statement)
+	accountRoutes.GET("/limit", user, accountHandler.Limit)
+
+	accountRoutes.GET("/my", user, accountHandler.My)
+
+	// grouping route with /account/schedule
+	scheduleHandler := handler.NewSchedule(db, middleware.StepUpCheck(db, cfg.Limits.StepUpAmount))
+	scheduleRoutes := accountRoutes.Group("/schedule", user)
+	scheduleRoutes.POST("/create", scheduleHandler.Create)
+	scheduleRoutes.GET("/read/:id", scheduleHandler.Read)
+	scheduleRoutes.PATCH("/update/:id", scheduleHandler.Update)
+	scheduleRoutes.DELETE("/delete/:id", scheduleHandler.Delete)
+	scheduleRoutes.GET("/list", scheduleHandler.List)
 
 	// grouping route with /transactionCategories
 	transacttionCTGHandler := handler.NewTransactionCategories(db)
-	transacttionCTGRoutes := r.Group("/transcat")
-	transacttionCTGRoutes.POST("/create", transacttionCTGHandler.Create)
+	transacttionCTGRoutes := r.Group("/transcat", authMiddleware)
+	transacttionCTGRoutes.POST("/create", admin, transacttionCTGHandler.Create)
 	transacttionCTGRoutes.GET("/read/:id", transacttionCTGHandler.Read)
-	transacttionCTGRoutes.PATCH("/update/:id", transacttionCTGHandler.Update)
-	transacttionCTGRoutes.DELETE("/delete/:id", transacttionCTGHandler.Delete)
+	transacttionCTGRoutes.PATCH("/update/:id", admin, transacttionCTGHandler.Update)
+	transacttionCTGRoutes.DELETE("/delete/:id", admin, transacttionCTGHandler.Delete)
 	transacttionCTGRoutes.GET("/list", transacttionCTGHandler.List)
 
+	// grouping route with /fx
+	fxHandler := handler.NewFx(db)
+	fxRoutes := r.Group("/fx", authMiddleware)
+	fxRoutes.POST("/create", admin, fxHandler.Create)
+	fxRoutes.GET("/list", fxHandler.List)
+
 	// grouping route with /transaction
-	transactionHandler := handler.NewTransaction(db)
-	transactionRoutes := r.Group("/transaction")
-	transactionRoutes.POST("/create", middleware.AuthMiddleware(signingKey), transactionHandler.NewTransaction)
-	transactionRoutes.GET("/list", middleware.AuthMiddleware(signingKey), transactionHandler.TransactionList)
+	transactionHandler := handler.NewTransaction(db, accountService)
+	transactionRoutes := r.Group("/transaction", authMiddleware)
+	// Record manual tanpa posting jurnal, hanya untuk staff agar nasabah tidak bisa
+	// memalsukan transaksi atas nama akun lain
+	transactionRoutes.POST("/create", staff, middleware.Idempotency(db), transactionHandler.NewTransaction)
+	transactionRoutes.GET("/list", transactionHandler.TransactionList)
+	transactionRoutes.POST("/:id/reverse", middleware.Idempotency(db), transactionHandler.Reverse)
+
+	// grouping route with /admin, hanya untuk staff
+	adminRoutes := r.Group("/admin", authMiddleware, staff)
+
+	// grouping route with /admin/reconciliation; features.reconcile_auto_freeze membekukan akun yang selisih
+	reconciliationHandler := handler.NewReconciliation(db, cfg.Features.ReconcileAutoFreeze)
+	reconciliationRoutes := adminRoutes.Group("/reconciliation")
+	reconciliationRoutes.POST("/run", admin, reconciliationHandler.Run)
+	reconciliationRoutes.GET("/read/:id", reconciliationHandler.Read)
+	reconciliationRoutes.GET("/list", reconciliationHandler.List)
+	reconciliationRoutes.PATCH("/resolve/:id", admin, reconciliationHandler.Resolve)
+
+	// grouping route with /admin/limit
+	limitHandler := handler.NewLimit(db)
+	limitRoutes := adminRoutes.Group("/limit")
+	limitRoutes.POST("/upsert", admin, limitHandler.Upsert)
+	limitRoutes.GET("/list", limitHandler.List)
+	limitRoutes.DELETE("/delete/:id", admin, limitHandler.Delete)
 
-	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
+	// grouping route with /admin/lockout
+	lockoutHandler := handler.NewLockout(db)
+	lockoutRoutes := adminRoutes.Group("/lockout")
+	lockoutRoutes.GET("/list", lockoutHandler.List)
+	lockoutRoutes.POST("/unlock", admin, lockoutHandler.Unlock)
+	lockoutRoutes.GET("/events", lockoutHandler.Events)
+
+	// grouping route with /admin/service dan /admin/apikey untuk client service-to-service
+	apiKeyHandler := handler.NewAPIKey(db)
+	serviceRoutes := adminRoutes.Group("/service", admin)
+	serviceRoutes.POST("/create", apiKeyHandler.CreatePrincipal)
+	serviceRoutes.GET("/list", apiKeyHandler.ListPrincipals)
+	serviceRoutes.PATCH("/disable/:id", apiKeyHandler.DisablePrincipal)
+	serviceRoutes.POST("/account/grant", apiKeyHandler.GrantAccount)
+	serviceRoutes.GET("/account/list/:id", apiKeyHandler.ListAccounts)
+	serviceRoutes.DELETE("/account/revoke/:id/:account_id", apiKeyHandler.RevokeAccount)
+	apiKeyRoutes := adminRoutes.Group("/apikey", admin)
+	apiKeyRoutes.POST("/create", apiKeyHandler.Create)
+	apiKeyRoutes.GET("/list", apiKeyHandler.List)
+	apiKeyRoutes.DELETE("/revoke/:id", apiKeyHandler.Revoke)
+
+	// Worker latar belakang berhenti setelah server selesai melayani request terakhir
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+
+	// Worker untuk menjalankan jadwal transfer yang jatuh tempo
+	if cfg.Features.Scheduler {
+		scheduleWorker := scheduler.NewWorker(db, cfg.Workers.SchedulerInterval)
+		readiness.Add("scheduler", scheduleWorker.Ready())
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			scheduleWorker.Run(workerCtx)
+		}()
+	}
+
+	// Job rekonsiliasi saldo, default harian
+	if cfg.Features.Reconcile {
+		reconcileWorker := reconcile.NewWorker(db, cfg.Workers.ReconcileInterval, cfg.Features.ReconcileAutoFreeze)
+		readiness.Add("reconcile", reconcileWorker.Ready())
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			reconcileWorker.Run(workerCtx)
+		}()
+	}
+
+	server := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Server.Port), // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	case <-signals.Done():
+	}
+	// Signal kedua langsung menghentikan proses
+	stopSignals()
+
+	// Graceful shutdown: /readyz gagal dulu, tunggu request yang sedang berjalan
+	// (misal transfer) selesai, lalu hentikan worker. Semuanya dibatasi
+	// server.shutdown_timeout.
+	slog.Info("shutting down")
+	readiness.SetDraining()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http server shutdown failed", "error", err)
+	}
+
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		slog.Info("shutdown complete")
+	case <-shutdownCtx.Done():
+		slog.Warn("shutdown timed out waiting for background workers")
+	}
 }
 
-func NewDatabase() *gorm.DB {
-	// dsn := "host=localhost port=5432 user=postgres dbname=digi sslmode=disable TimeZone=Asia/Jakarta"
-	dsn := os.Getenv("DATABASE")
-	if dsn == "" {
-		log.Fatal("DATABASE not set in environment")
+// NewKeyset memuat kunci JWT dari config:
+//   - jwt.signing_key (SIGNING_KEY): secret HS256 dengan kid jwt.signing_key_id (default "default")
+//   - jwt.keys (JWT_KEYS): kunci tambahan "kid=alg:path,..." (alg HS256, RS256 atau EdDSA)
+//   - jwt.active_kid (JWT_ACTIVE_KID): kunci untuk menandatangani token baru
+//
+// Rotasi: tambahkan kunci baru ke JWT_KEYS, pindahkan JWT_ACTIVE_KID, lalu hapus
+// kunci lama setelah semua access token lama kadaluarsa.
+func NewKeyset(cfg config.JWT) *keyset.Set {
+	keys := keyset.New(cfg.Issuer, cfg.Audience)
+
+	activeKID := cfg.ActiveKID
+	if cfg.SigningKey != "" {
+		if err := keys.AddHMAC(cfg.SigningKeyID, []byte(cfg.SigningKey)); err != nil {
+			log.Fatal("invalid SIGNING_KEY: ", err)
+		}
+		if activeKID == "" {
+			activeKID = cfg.SigningKeyID
+		}
+	}
+	if err := keys.LoadSpec(cfg.Keys); err != nil {
+		log.Fatal("invalid JWT_KEYS: ", err)
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err := keys.SetActive(activeKID); err != nil {
+		log.Fatalf("cannot use JWT key %q for signing: %v", activeKID, err)
+	}
+	return keys
+}
+
+// NewNotifier memilih kanal notifikasi: file jika notify.file (NOTIFY_FILE) diisi,
+// atau stdout jika notify.stdout (NOTIFY_STDOUT) aktif. Config.Validate memastikan
+// salah satunya dipilih.
+func NewNotifier(cfg config.Notify) notify.Notifier {
+	if cfg.File != "" {
+		return notify.NewFile(cfg.File)
+	}
+	return notify.NewWriter(os.Stdout)
+}
+
+// NewPasswordManager menyusun policy dan hasher password. Hasher utama dipakai
+// untuk hash baru; hash dengan hasher lain tetap bisa login dan di-rehash.
+func NewPasswordManager(cfg config.Password) *password.Manager {
+	policy := password.DefaultPolicy
+	policy.MinLength = cfg.MinLength
+	if err := policy.ParseClasses(cfg.Classes); err != nil {
+		log.Fatal("invalid PASSWORD_CLASSES: ", err)
+	}
+
+	bcryptHasher := password.Bcrypt{Cost: cfg.BcryptCost}
+	if cfg.Hasher == "bcrypt" {
+		return password.NewManager(policy, bcryptHasher, password.DefaultArgon2id)
+	}
+	return password.NewManager(policy, password.DefaultArgon2id, bcryptHasher)
+}
+
+// NewLogger membuat logger slog sesuai log.format dan log.level. Attribute dengan
+// nama sensitif (password, token, secret, ...) disamarkan, dan request_id serta
+// account_id diambil dari context.
+func NewLogger(cfg config.Log) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		log.Fatal("invalid LOG_LEVEL: ", err)
+	}
+
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: logging.ReplaceAttr}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, options)
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(os.Stdout, options)
+	}
+	return slog.New(logging.NewHandler(handler))
+}
+
+func NewDatabase(cfg config.Database, logger *logging.Gorm) *gorm.DB {
+	// dsn := "host=localhost port=5432 user=postgres dbname=digi sslmode=disable TimeZone=Asia/Jakarta"
+	db, err := gorm.Open(postgres.Open(cfg.DSN), &gorm.Config{Logger: logger})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -106,13 +444,19 @@ func NewDatabase() *gorm.DB {
 		log.Fatalf("failed to get DB object: %v", err)
 	}
 
+	// Pool koneksi; nilai 0 berarti tidak dibatasi
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
 	var currentDB string
 	err = sqlDB.QueryRow("SELECT current_database()").Scan(&currentDB)
 	if err != nil {
 		log.Fatalf("failed to query current database: %v", err)
 	}
 
-	log.Printf("Current Database: %s\n", currentDB)
+	slog.Info("connected to database", "database", currentDB)
 
 	return db
 }
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"task-golang-batch2/apikey"
+	"task-golang-batch2/keyset"
+	"task-golang-batch2/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// APIKeyHeader berisi API key milik service principal
+const APIKeyHeader = "X-API-Key"
+
+// scopeGrantedKey menandai bahwa RequireScope sudah meloloskan service principal
+const scopeGrantedKey = "scope_granted"
+
+// ServiceAuthMiddleware menerima API key (header X-API-Key) selain JWT bearer.
+// Request dengan API key tidak punya auth_id/account_id; role-nya "service" dan
+// route harus memakai RequireScope agar bisa diakses.
+func ServiceAuthMiddleware(keys *keyset.Set, db *gorm.DB) gin.HandlerFunc {
+	jwtAuth := AuthMiddleware(keys, db)
+	return func(c *gin.Context) {
+		rawKey := c.GetHeader(APIKeyHeader)
+		if rawKey == "" || c.GetHeader("Authorization") != "" {
+			jwtAuth(c)
+			return
+		}
+
+		key, err := apikey.Authenticate(db, rawKey, c.ClientIP())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: invalid api key"})
+			return
+		}
+
+		c.Set("role", model.RoleService)
+		c.Set("service_principal_id", key.ServicePrincipalID)
+		c.Set("api_key_id", key.APIKeyID)
+		c.Set("scopes", key.ScopeList())
+		c.Next()
+	}
+}
+
+// RequireScope mewajibkan scope untuk request dengan API key dan meloloskannya dari
+// pemeriksaan role berikutnya. Request user biasa diteruskan ke pemeriksaan role.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != model.RoleService {
+			c.Next()
+			return
+		}
+
+		for _, granted := range c.GetStringSlice("scopes") {
+			if granted == scope {
+				c.Set(scopeGrantedKey, true)
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: missing scope " + scope})
+	}
+}
+
+// RequireUser menolak API key pada route yang bekerja atas akun milik user login
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") == model.RoleService {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: user token required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,17 +1,21 @@
 package middleware
 
 import (
-	"errors"
 	"net/http"
 	"strings"
+	"task-golang-batch2/keyset"
+	"task-golang-batch2/logging"
+	"task-golang-batch2/model"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 // Middleware untuk memeriksa token dan menambahkan klaim ke context
-// AuthMiddleware untuk memvalidasi token JWT
-func AuthMiddleware(secretKey string) gin.HandlerFunc {
+// AuthMiddleware untuk memvalidasi token JWT, termasuk token yang sudah dicabut
+// (logout berdasarkan jti) atau token versi lama (setelah ganti password)
+func AuthMiddleware(keys *keyset.Set, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Mengambil header Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -31,44 +35,98 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		// Mem-parse token JWT
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Memeriksa metode signing token
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(secretKey), nil
-		})
+		// Mem-parse token JWT: kid, algoritma, iss, aud, exp dan nbf divalidasi oleh keyset
+		claims, err := keys.Parse(tokenString)
 
 		// Jika token tidak valid
-		if err != nil || !token.Valid {
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: invalid token"})
 			c.Abort()
 			return
 		}
 
 		// Mengambil klaim dari token dan menyimpannya di context
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			if authID, ok := claims["auth_id"].(float64); ok {
-				c.Set("auth_id", int64(authID))
-			}
-			if accountID, ok := claims["account_id"].(float64); ok {
-				c.Set("account_id", int64(accountID))
-			}
-			// if username, ok := claims["username"].(string); ok {
-			// 	c.Set("username", username)
-			// }
-		} else {
+
+		authID, _ := claims["auth_id"].(float64)
+		c.Set("auth_id", int64(authID))
+		if accountID, ok := claims["account_id"].(float64); ok {
+			c.Set("account_id", int64(accountID))
+			logging.SetAccountID(c.Request.Context(), int64(accountID))
+		}
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = model.RoleCustomer
+		}
+		c.Set("role", role)
+		// if username, ok := claims["username"].(string); ok {
+		// 	c.Set("username", username)
+		// }
+
+		// Hanya access token yang diterima, bukan challenge 2FA
+		jti, _ := claims["jti"].(string)
+		version, _ := claims["ver"].(float64)
+		sessionID, _ := claims["sid"].(float64)
+		if jti == "" || sessionID == 0 || claims["typ"] != "access" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: invalid claims"})
 			c.Abort()
 			return
 		}
+		c.Set("jti", jti)
+		c.Set("session_id", int64(sessionID))
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			c.Set("token_exp", exp.Time)
+		}
+
+		// Tolak token yang sudah di-logout, session-nya dicabut, atau diterbitkan sebelum password diganti
+		if revoked, err := isRevoked(db, int64(authID), jti, int64(version), int64(sessionID)); err != nil || revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: token revoked"})
+			c.Abort()
+			return
+		}
 
 		// Jika token valid, lanjutkan ke handler berikutnya
 		c.Next()
 	}
 }
 
+// Last seen session hanya diperbarui jika lebih lama dari ini
+const sessionSeenResolution = time.Minute
+
+// isRevoked memeriksa denylist jti, versi token milik user, dan status session
+func isRevoked(db *gorm.DB, authID int64, jti string, version int64, sessionID int64) (bool, error) {
+	var auth model.Auth
+	if err := db.Select("token_version").Where("auth_id = ?", authID).First(&auth).Error; err != nil {
+		return true, err
+	}
+	if auth.TokenVersion != version {
+		return true, nil
+	}
+
+	var count int64
+	if err := db.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return true, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	var session model.Session
+	if err := db.Select("revoked_at", "last_seen_at").
+		Where("session_id = ? AND auth_id = ?", sessionID, authID).
+		First(&session).Error; err != nil {
+		return true, err
+	}
+	if session.RevokedAt != nil {
+		return true, nil
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) > sessionSeenResolution {
+		db.Model(&model.Session{}).Where("session_id = ?", sessionID).Update("last_seen_at", now)
+	}
+	return false, nil
+}
+
 // func AuthMiddleware(secretKey string) gin.HandlerFunc {
 // 	return func(c *gin.Context) {
 // 		// Ambil token dari header Authorization
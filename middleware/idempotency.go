@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"task-golang-batch2/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	IdempotencyHeader = "Idempotency-Key"
+	// Key yang lebih tua dari ini boleh dipakai ulang untuk request baru
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
+// responseRecorder menyalin body response supaya bisa disimpan dan diputar ulang
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotency menangani header Idempotency-Key untuk endpoint yang memindahkan uang.
+// Request pertama diproses dan response-nya disimpan; request ulang dengan key dan body
+// yang sama mendapat response yang sama, sedangkan key yang sama dengan body berbeda ditolak.
+// Pasang setelah AuthMiddleware agar key dibatasi per account_id.
+func Idempotency(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		// API key tidak punya account_id; pisahkan key per service principal
+		if principalID := c.GetInt64("service_principal_id"); principalID != 0 {
+			key = fmt.Sprintf("svc:%d:%s", principalID, key)
+		}
+		if len(key) > 255 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key too long"})
+			return
+		}
+
+		// Baca body untuk fingerprint lalu kembalikan supaya handler tetap bisa membacanya
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.FullPath() + "\n" + c.ContentType() + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		accountID := c.GetInt64("account_id")
+
+		// Hapus key kadaluarsa supaya bisa diklaim ulang. Jika gagal, response lama
+		// bisa terputar ulang untuk request baru, jadi request dihentikan.
+		if err := db.Where("account_id = ? AND key = ? AND created_at < ?", accountID, key, time.Now().Add(-idempotencyKeyTTL)).
+			Delete(&model.IdempotencyKey{}).Error; err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to delete expired idempotency key", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to store idempotency key"})
+			return
+		}
+
+		// Klaim key; jika sudah ada, gunakan record yang tersimpan
+		record := model.IdempotencyKey{
+			AccountID:   accountID,
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.FullPath(),
+			RequestHash: fingerprint,
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to store idempotency key"})
+			return
+		}
+
+		if result.RowsAffected == 0 {
+			var existing model.IdempotencyKey
+			if err := db.Where("account_id = ? AND key = ?", accountID, key).First(&existing).Error; err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load idempotency key"})
+				return
+			}
+
+			if existing.RequestHash != fingerprint {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key already used with a different request"})
+				return
+			}
+
+			if existing.ResponseCode == 0 {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+				return
+			}
+
+			// Putar ulang response asli beserta header-nya
+			for name, values := range existing.ResponseHeaders {
+				c.Writer.Header()[name] = values
+			}
+			c.Header("Idempotent-Replayed", "true")
+			contentType := existing.ResponseHeaders.Get("Content-Type")
+			if contentType == "" {
+				contentType = "application/json; charset=utf-8"
+			}
+			c.Data(existing.ResponseCode, contentType, existing.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// Header yang sudah diset middleware sebelumnya (request id, CORS) bukan bagian
+		// dari response handler dan tidak disimpan
+		before := c.Writer.Header().Clone()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		// Jika handler panic, key dilepas supaya tidak tertahan "masih diproses" sampai
+		// kadaluarsa; panic diteruskan ke middleware Recovery
+		defer func() {
+			if r := recover(); r != nil {
+				releaseIdempotencyKey(c, db, record.IdempotencyKeyID)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		// Error server dan penolakan auth/step-up (misal kode 2FA belum dikirim) tidak
+		// disimpan agar klien bisa mencoba lagi dengan key yang sama
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusUnauthorized || status == http.StatusForbidden {
+			releaseIdempotencyKey(c, db, record.IdempotencyKeyID)
+			return
+		}
+
+		headers := http.Header{}
+		for name, values := range recorder.Header() {
+			if name == "Content-Length" || name == "Date" || slices.Equal(before[name], values) {
+				continue
+			}
+			headers[name] = values
+		}
+
+		// Update lewat struct supaya serializer JSON untuk header dipakai. Dicoba sekali
+		// lagi jika gagal; jika tetap gagal key dilepas supaya retry klien tidak tertahan
+		// "masih diproses" sampai kadaluarsa.
+		response := model.IdempotencyKey{
+			ResponseCode:    status,
+			ResponseBody:    recorder.body.Bytes(),
+			ResponseHeaders: headers,
+		}
+		var saveErr error
+		for attempt := 0; attempt < 2; attempt++ {
+			saveErr = db.Model(&model.IdempotencyKey{}).Where("idempotency_key_id = ?", record.IdempotencyKeyID).
+				Select("response_code", "response_body", "response_headers").
+				Updates(&response).Error
+			if saveErr == nil {
+				return
+			}
+		}
+		slog.ErrorContext(c.Request.Context(), "failed to save idempotent response", "idempotency_key_id", record.IdempotencyKeyID, "error", saveErr)
+		releaseIdempotencyKey(c, db, record.IdempotencyKeyID)
+	}
+}
+
+// releaseIdempotencyKey menghapus key yang sudah diklaim supaya bisa dipakai lagi
+func releaseIdempotencyKey(c *gin.Context, db *gorm.DB, id int64) {
+	if err := db.Delete(&model.IdempotencyKey{}, id).Error; err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to release idempotency key", "idempotency_key_id", id, "error", err)
+	}
+}
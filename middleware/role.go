@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func hasRole(c *gin.Context, roles []string) bool {
+	// Service principal yang sudah lolos RequireScope diperlakukan seperti role yang diizinkan
+	if c.GetBool(scopeGrantedKey) {
+		return true
+	}
+
+	role := c.GetString("role")
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole hanya meneruskan request jika role di token termasuk roles.
+// Pasang setelah AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasRole(c, roles) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: insufficient role"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireOwnAccount memastikan parameter URL param sama dengan account_id di token,
+// kecuali role termasuk privileged (misalnya support dan admin boleh melihat akun lain).
+func RequireOwnAccount(param string, privileged ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasRole(c, privileged) {
+			c.Next()
+			return
+		}
+
+		accountID, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if err != nil || accountID != c.GetInt64("account_id") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: not your account"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"task-golang-batch2/lockout"
+	"task-golang-batch2/model"
+	"task-golang-batch2/totp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// StepUpHeader berisi kode TOTP (atau kode cadangan) untuk verifikasi tambahan
+const StepUpHeader = "X-TOTP-Code"
+
+// StepUp mewajibkan verifikasi 2FA untuk transfer dengan field form "amount" di atas
+// threshold. threshold <= 0 berarti step-up tidak aktif. Pasang setelah AuthMiddleware.
+func StepUp(db *gorm.DB, threshold int64) gin.HandlerFunc {
+	check := StepUpCheck(db, threshold)
+	return func(c *gin.Context) {
+		amount, err := strconv.ParseInt(c.PostForm("amount"), 10, 64)
+		if err != nil {
+			// Validasi amount tetap dilakukan oleh handler
+			c.Next()
+			return
+		}
+		if check(c, amount) {
+			c.Next()
+		}
+	}
+}
+
+// StepUpCheck adalah aturan StepUp untuk handler yang baru tahu nominalnya setelah
+// membaca body atau data tersimpan, misal jadwal transfer. Fungsi yang dikembalikan
+// meloloskan amount <= threshold; di atasnya kode 2FA di header StepUpHeader wajib
+// valid, jika tidak request di-abort dan hasilnya false.
+func StepUpCheck(db *gorm.DB, threshold int64) func(c *gin.Context, amount int64) bool {
+	return func(c *gin.Context, amount int64) bool {
+		// 2FA hanya berlaku untuk user; API key dibatasi lewat scope
+		if threshold <= 0 || amount <= threshold || c.GetString("role") == model.RoleService {
+			return true
+		}
+
+		var auth model.Auth
+		if err := db.Where("auth_id = ?", c.GetInt64("auth_id")).First(&auth).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return false
+		}
+
+		if !auth.TOTPEnabled {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":            "Two-factor authentication must be enabled for transfers above " + strconv.FormatInt(threshold, 10),
+				"step_up_required": true,
+			})
+			return false
+		}
+
+		code := c.GetHeader(StepUpHeader)
+		if code == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":            "Two-factor code required in " + StepUpHeader + " header",
+				"step_up_required": true,
+			})
+			return false
+		}
+
+		// Percobaan dipesan sebelum kode diverifikasi, sama seperti LoginTwoFactor
+		subject := lockout.StepUp(auth.Username)
+		wait, err := lockout.Reserve(db, time.Now(), subject)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor attempts"})
+			return false
+		}
+		if wait > 0 {
+			seconds := int64(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Too many failed two-factor attempts, try again later",
+				"retry_after": seconds,
+			})
+			return false
+		}
+
+		ok, err := totp.Verify(db, &auth, code)
+		if err != nil {
+			if err := lockout.Release(db, subject); err != nil {
+				slog.ErrorContext(c.Request.Context(), "failed to release step-up attempt", "auth_id", auth.AuthID, "error", err)
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+			return false
+		}
+		if !ok {
+			failure := lockout.Failure{AuthID: &auth.AuthID, Username: auth.Username, IPAddress: c.ClientIP()}
+			if _, err := lockout.RecordFailure(db, time.Now(), subject, failure); err != nil {
+				slog.ErrorContext(c.Request.Context(), "failed to record step-up failure", "auth_id", auth.AuthID, "error", err)
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":            "Invalid two-factor code",
+				"step_up_required": true,
+			})
+			return false
+		}
+		if err := lockout.Reset(db, subject); err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to reset step-up attempts", "auth_id", auth.AuthID, "error", err)
+		}
+		return true
+	}
+}
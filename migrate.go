@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"task-golang-batch2/migrations"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: migrate <command>
+  up [n]              jalankan n migrasi berikutnya (default semua)
+  down [n]            batalkan n migrasi terakhir (default 1)
+  status              tampilkan migrasi yang sudah dan belum dijalankan
+  baseline [version]  tandai migrasi sampai version sudah dijalankan tanpa
+                      mengeksekusinya, untuk database dari SQL manual (default 1,
+                      yaitu skema asli digi-Rivanapta.sql; lanjutkan dengan up)`
+
+// RunMigrate menjalankan subcommand "migrate". args adalah argumen setelah "migrate".
+func RunMigrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New(migrateUsage)
+	}
+
+	count := func(fallback int) (int, error) {
+		if len(args) < 2 {
+			return fallback, nil
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("migrate %s: %q is not a valid number", args[0], args[1])
+		}
+		return n, nil
+	}
+
+	switch args[0] {
+	case "up":
+		steps, err := count(0)
+		if err != nil {
+			return err
+		}
+		applied, err := migrations.Up(db, steps)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return err
+	case "down":
+		steps, err := count(1)
+		if err != nil {
+			return err
+		}
+		reverted, err := migrations.Down(db, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrations.Statuses(db)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				appliedAt += " (modified)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	case "baseline":
+		version, err := count(1)
+		if err != nil {
+			return err
+		}
+		marked, err := migrations.Baseline(db, int64(version))
+		for _, migration := range marked {
+			fmt.Printf("marked %d_%s as applied\n", migration.Version, migration.Name)
+		}
+		return err
+	default:
+		return errors.New(migrateUsage)
+	}
+}
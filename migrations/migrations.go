@@ -0,0 +1,357 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Script migrasi: sql/<versi>_<nama>.up.sql dan .down.sql, versi berurutan mulai 1
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID adalah kunci pg_advisory_xact_lock supaya dua instance tidak migrasi bersamaan
+const lockID = 7283410021
+
+var (
+	ErrOutdated         = errors.New("database schema is outdated, run \"migrate up\"")
+	ErrChecksumMismatch = errors.New("applied migration was modified after it ran")
+	ErrUnknownVersion   = errors.New("database has migrations unknown to this binary")
+)
+
+// Migration adalah satu versi skema beserta script naik dan turunnya
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 dari script up, dicatat saat dijalankan
+}
+
+// History adalah baris schema_migrations
+type History struct {
+	Version   int64     `gorm:"primaryKey"`
+	Name      string    `gorm:"not null"`
+	Checksum  string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (History) TableName() string {
+	return "schema_migrations"
+}
+
+// Status adalah keadaan satu migrasi di database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // Checksum berbeda dari yang tercatat
+}
+
+// Load membaca migrasi yang di-embed, diurutkan berdasarkan versi
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		base, direction, ok := cutDirection(name)
+		if !ok {
+			return nil, fmt.Errorf("migration %s: name must end with .up.sql or .down.sql", name)
+		}
+		versionStr, title, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version", name)
+		}
+
+		content, err := files.ReadFile(path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: title}
+			byVersion[version] = migration
+		}
+		if migration.Name != title {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, title)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down scripts", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			return nil, fmt.Errorf("migration versions must be sequential, missing version %d", i+1)
+		}
+	}
+	return migrations, nil
+}
+
+func cutDirection(name string) (base, direction string, ok bool) {
+	if base, ok = strings.CutSuffix(name, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok = strings.CutSuffix(name, ".down.sql"); ok {
+		return base, "down", true
+	}
+	return "", "", false
+}
+
+// ensureHistory membuat tabel schema_migrations jika belum ada
+func ensureHistory(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS public.schema_migrations (
+	version int8 NOT NULL,
+	"name" varchar NOT NULL,
+	checksum varchar NOT NULL,
+	applied_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT schema_migrations_pk PRIMARY KEY (version)
+)`).Error
+}
+
+// applied membaca history; tabel yang belum ada berarti belum ada migrasi yang jalan
+func applied(db *gorm.DB) (map[int64]History, error) {
+	if !db.Migrator().HasTable(&History{}) {
+		return map[int64]History{}, nil
+	}
+
+	var rows []History
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int64]History, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// Statuses mengembalikan semua migrasi yang dikenal beserta status di database.
+// Hanya membaca, sehingga aman dipanggil dari readiness probe.
+func Statuses(db *gorm.DB) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	history, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		status := Status{Migration: migration}
+		if row, ok := history[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &row.AppliedAt
+			status.Modified = row.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Check dipanggil saat startup: gagal jika ada migrasi yang belum dijalankan,
+// migrasi yang sudah jalan diubah, atau database lebih baru dari binary ini.
+func Check(db *gorm.DB) error {
+	statuses, err := Statuses(db)
+	if err != nil {
+		return err
+	}
+	history, err := applied(db)
+	if err != nil {
+		return err
+	}
+
+	// Setiap versi di history harus dikenal binary ini; membandingkan jumlah saja
+	// tidak cukup jika ada migrasi yang belum jalan sekaligus versi yang tidak dikenal
+	known := make(map[int64]bool, len(statuses))
+	for _, status := range statuses {
+		known[status.Version] = true
+	}
+	var unknown []int64
+	for version := range history {
+		if !known[version] {
+			unknown = append(unknown, version)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+		names := make([]string, 0, len(unknown))
+		for _, version := range unknown {
+			names = append(names, fmt.Sprintf("%d_%s", version, history[version].Name))
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownVersion, strings.Join(names, ", "))
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.Modified {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, status.Version, status.Name)
+		}
+		if !status.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w (pending: %s)", ErrOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up menjalankan maksimal steps migrasi yang belum dijalankan (0 berarti semua).
+// Setiap migrasi berjalan dalam transaksinya sendiri bersama pencatatan history.
+func Up(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureHistory(db); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if steps > 0 && len(done) == steps {
+			break
+		}
+
+		ran := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+				return err
+			}
+			// Cek ulang setelah lock, instance lain mungkin sudah menjalankannya
+			var count int64
+			if err := tx.Model(&History{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Create(&History{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down membatalkan steps migrasi terakhir yang sudah dijalankan, dari yang terbaru
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureHistory(db); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+
+		ran := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+				return err
+			}
+			result := tx.Where("version = ?", migration.Version).Delete(&History{})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			ran = true
+			return tx.Exec(migration.Down).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Baseline menandai migrasi sampai version sebagai sudah dijalankan tanpa
+// mengeksekusi script-nya, untuk database yang dibuat dari SQL manual
+func Baseline(db *gorm.DB, version int64) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if version <= 0 || version > int64(len(migrations)) {
+		return nil, fmt.Errorf("baseline version must be between 1 and %d", len(migrations))
+	}
+	if err := ensureHistory(db); err != nil {
+		return nil, err
+	}
+
+	var marked []Migration
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+			return err
+		}
+		for _, migration := range migrations[:version] {
+			result := tx.Exec(`INSERT INTO public.schema_migrations (version, "name", checksum, applied_at)
+VALUES (?, ?, ?, ?) ON CONFLICT (version) DO NOTHING`,
+				migration.Version, migration.Name, migration.Checksum, time.Now())
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				marked = append(marked, migration)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return marked, nil
+}
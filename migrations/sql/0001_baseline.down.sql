@@ -0,0 +1,6 @@
+-- Menghapus seluruh skema awal beserta datanya
+DROP TABLE IF EXISTS
+	public."transaction",
+	public.transaction_categories,
+	public.auths,
+	public.accounts;
@@ -1,3 +1,6 @@
+-- Skema awal, sama persis dengan digi-Rivanapta.sql yang sebelumnya dijalankan manual.
+-- Database yang dibuat dari file tersebut ditandai dengan "migrate baseline" (versi 1),
+-- lalu "migrate up" menambahkan tabel dan kolom fitur dari migrasi berikutnya.
 
 -- DDL
 CREATE TABLE public.accounts (
@@ -19,14 +22,12 @@ CREATE TABLE public.auths (
 	CONSTRAINT auths_unique UNIQUE (account_id)
 );
 
-
 CREATE TABLE public.transaction_categories (
 	transaction_category_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
 	"name" varchar NOT NULL,
 	CONSTRAINT transaction_categories_pk PRIMARY KEY (transaction_category_id)
 );
 
-
 CREATE TABLE public."transaction" (
 	transaction_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
 	transaction_category_id int8 NULL,
@@ -39,4 +40,3 @@ CREATE TABLE public."transaction" (
 	CONSTRAINT transaction_category_id FOREIGN KEY (transaction_category_id) REFERENCES public."transaction"(transaction_id)
 );
 
-
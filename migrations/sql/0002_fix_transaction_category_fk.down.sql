@@ -0,0 +1,3 @@
+-- Hanya melepas FK yang benar; FK lama yang salah tidak dipasang kembali
+DROP INDEX IF EXISTS public.transaction_category_idx;
+ALTER TABLE public."transaction" DROP CONSTRAINT IF EXISTS transaction_category_fk;
@@ -0,0 +1,17 @@
+-- transaction_category_id sebelumnya mereferensikan transaction(transaction_id)
+-- sehingga kategori yang valid ditolak. Kategori yang tidak ada dikosongkan dulu
+-- supaya constraint baru bisa dibuat.
+ALTER TABLE public."transaction" DROP CONSTRAINT IF EXISTS transaction_category_id;
+
+UPDATE public."transaction" t SET transaction_category_id = NULL
+WHERE transaction_category_id IS NOT NULL
+	AND NOT EXISTS (
+		SELECT 1 FROM public.transaction_categories c
+		WHERE c.transaction_category_id = t.transaction_category_id
+	);
+
+ALTER TABLE public."transaction"
+	ADD CONSTRAINT transaction_category_fk FOREIGN KEY (transaction_category_id)
+	REFERENCES public.transaction_categories(transaction_category_id);
+
+CREATE INDEX transaction_category_idx ON public."transaction" (transaction_category_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS public.journal_lines, public.journal_entries;
+DELETE FROM public.accounts WHERE is_system;
+ALTER TABLE public.accounts
+	DROP CONSTRAINT IF EXISTS accounts_code_unique,
+	DROP COLUMN IF EXISTS is_system,
+	DROP COLUMN IF EXISTS code;
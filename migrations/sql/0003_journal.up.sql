@@ -0,0 +1,35 @@
+-- Jurnal double-entry untuk top-up, transfer dan perubahan saldo
+ALTER TABLE public.accounts
+	ADD COLUMN code varchar NULL,
+	ADD COLUMN is_system bool DEFAULT false NOT NULL,
+	ADD CONSTRAINT accounts_code_unique UNIQUE (code);
+
+CREATE TABLE public.journal_entries (
+	journal_entry_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	transaction_id int8 NULL,
+	description varchar NOT NULL,
+	created_at timestamp DEFAULT now() NOT NULL,
+	CONSTRAINT journal_entries_pk PRIMARY KEY (journal_entry_id),
+	CONSTRAINT journal_entries_transaction_fk FOREIGN KEY (transaction_id) REFERENCES public."transaction"(transaction_id)
+);
+
+
+CREATE TABLE public.journal_lines (
+	journal_line_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	journal_entry_id int8 NOT NULL,
+	account_id int8 NOT NULL,
+	debit int8 DEFAULT 0 NOT NULL,
+	credit int8 DEFAULT 0 NOT NULL,
+	CONSTRAINT journal_lines_pk PRIMARY KEY (journal_line_id),
+	CONSTRAINT journal_lines_entry_fk FOREIGN KEY (journal_entry_id) REFERENCES public.journal_entries(journal_entry_id),
+	CONSTRAINT journal_lines_account_fk FOREIGN KEY (account_id) REFERENCES public.accounts(account_id),
+	CONSTRAINT journal_lines_one_side CHECK ((debit > 0 AND credit = 0) OR (credit > 0 AND debit = 0))
+);
+
+CREATE INDEX journal_lines_account_idx ON public.journal_lines (account_id);
+
+-- Akun sistem untuk sumber dana top-up, pendapatan fee dan penyesuaian manual
+INSERT INTO public.accounts ("name", balance, code, is_system) VALUES
+	('System Top-Up Funding', 0, 'SYS_TOPUP_FUNDING', true),
+	('System Fees', 0, 'SYS_FEES', true),
+	('System Adjustment', 0, 'SYS_ADJUSTMENT', true);
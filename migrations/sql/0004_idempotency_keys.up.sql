@@ -0,0 +1,13 @@
+CREATE TABLE public.idempotency_keys (
+	idempotency_key_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 DEFAULT 0 NOT NULL,
+	"key" varchar(255) NOT NULL,
+	"method" varchar NOT NULL,
+	"path" varchar NOT NULL,
+	request_hash varchar NOT NULL,
+	response_code int4 DEFAULT 0 NOT NULL,
+	response_body bytea NULL,
+	created_at timestamp DEFAULT now() NOT NULL,
+	CONSTRAINT idempotency_keys_pk PRIMARY KEY (idempotency_key_id),
+	CONSTRAINT idempotency_keys_unique UNIQUE (account_id, "key")
+);
@@ -0,0 +1,6 @@
+ALTER TABLE public."transaction"
+	DROP CONSTRAINT IF EXISTS transaction_reversed_amount_check,
+	DROP CONSTRAINT IF EXISTS transaction_reversal_of_fk,
+	DROP COLUMN IF EXISTS reversed_amount,
+	DROP COLUMN IF EXISTS reversal_of_id,
+	DROP COLUMN IF EXISTS "type";
@@ -0,0 +1,7 @@
+-- Jenis transaksi dan refund penuh / sebagian
+ALTER TABLE public."transaction"
+	ADD COLUMN "type" varchar NULL,
+	ADD COLUMN reversal_of_id int8 NULL,
+	ADD COLUMN reversed_amount int8 DEFAULT 0 NOT NULL,
+	ADD CONSTRAINT transaction_reversal_of_fk FOREIGN KEY (reversal_of_id) REFERENCES public."transaction"(transaction_id),
+	ADD CONSTRAINT transaction_reversed_amount_check CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.scheduled_transfer_runs, public.scheduled_transfers;
@@ -0,0 +1,38 @@
+CREATE TABLE public.scheduled_transfers (
+	scheduled_transfer_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	to_account_id int8 NOT NULL,
+	amount int8 NOT NULL,
+	frequency varchar NOT NULL,
+	cron_expr varchar NULL,
+	start_at timestamptz NOT NULL,
+	end_at timestamptz NULL,
+	next_run_at timestamptz NULL,
+	status varchar NOT NULL,
+	max_retries int4 DEFAULT 3 NOT NULL,
+	retry_count int4 DEFAULT 0 NOT NULL,
+	last_run_at timestamptz NULL,
+	last_error varchar NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT scheduled_transfers_pk PRIMARY KEY (scheduled_transfer_id),
+	CONSTRAINT scheduled_transfers_account_fk FOREIGN KEY (account_id) REFERENCES public.accounts(account_id),
+	CONSTRAINT scheduled_transfers_to_account_fk FOREIGN KEY (to_account_id) REFERENCES public.accounts(account_id),
+	CONSTRAINT scheduled_transfers_amount_check CHECK (amount > 0)
+);
+
+CREATE INDEX scheduled_transfers_due_idx ON public.scheduled_transfers (status, next_run_at);
+
+
+CREATE TABLE public.scheduled_transfer_runs (
+	scheduled_transfer_run_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	scheduled_transfer_id int8 NOT NULL,
+	transaction_id int8 NULL,
+	success bool NOT NULL,
+	error varchar NULL,
+	attempt int4 NOT NULL,
+	executed_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT scheduled_transfer_runs_pk PRIMARY KEY (scheduled_transfer_run_id),
+	CONSTRAINT scheduled_transfer_runs_schedule_fk FOREIGN KEY (scheduled_transfer_id) REFERENCES public.scheduled_transfers(scheduled_transfer_id),
+	CONSTRAINT scheduled_transfer_runs_transaction_fk FOREIGN KEY (transaction_id) REFERENCES public."transaction"(transaction_id)
+);
@@ -0,0 +1,15 @@
+-- Hanya bisa dibatalkan selama semua data masih IDR
+DROP TABLE IF EXISTS public.fx_rates;
+DELETE FROM public.accounts WHERE is_system AND code = 'SYS_FX';
+UPDATE public.accounts SET "name" = left("name", -4) WHERE is_system AND "name" LIKE '% IDR';
+
+ALTER TABLE public.journal_lines DROP COLUMN IF EXISTS currency;
+ALTER TABLE public."transaction"
+	DROP COLUMN IF EXISTS fx_rate,
+	DROP COLUMN IF EXISTS counter_currency,
+	DROP COLUMN IF EXISTS counter_amount,
+	DROP COLUMN IF EXISTS currency;
+ALTER TABLE public.accounts
+	DROP CONSTRAINT IF EXISTS accounts_code_unique,
+	DROP COLUMN IF EXISTS currency,
+	ADD CONSTRAINT accounts_code_unique UNIQUE (code);
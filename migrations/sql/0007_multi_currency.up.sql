@@ -0,0 +1,34 @@
+-- Akun multi mata uang dan konversi FX pada transfer. Data lama dianggap IDR.
+ALTER TABLE public.accounts
+	ADD COLUMN currency bpchar(3) DEFAULT 'IDR' NOT NULL,
+	DROP CONSTRAINT accounts_code_unique,
+	ADD CONSTRAINT accounts_code_unique UNIQUE (code, currency);
+
+ALTER TABLE public."transaction"
+	ADD COLUMN currency bpchar(3) NULL,
+	ADD COLUMN counter_amount int8 NULL,
+	ADD COLUMN counter_currency bpchar(3) NULL,
+	ADD COLUMN fx_rate numeric(24,12) NULL;
+
+ALTER TABLE public.journal_lines ADD COLUMN currency bpchar(3) DEFAULT 'IDR' NOT NULL;
+ALTER TABLE public.journal_lines ALTER COLUMN currency DROP DEFAULT;
+
+
+CREATE TABLE public.fx_rates (
+	fx_rate_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	base_currency bpchar(3) NOT NULL,
+	quote_currency bpchar(3) NOT NULL,
+	rate numeric(24,12) NOT NULL,
+	effective_at timestamptz NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT fx_rates_pk PRIMARY KEY (fx_rate_id),
+	CONSTRAINT fx_rates_rate_check CHECK (rate > 0)
+);
+
+CREATE INDEX fx_rates_pair_idx ON public.fx_rates (base_currency, quote_currency, effective_at DESC);
+
+-- Akun sistem yang ada menjadi akun IDR, ditambah akun posisi FX.
+-- Akun sistem untuk mata uang lain dibuat otomatis oleh aplikasi.
+UPDATE public.accounts SET "name" = "name" || ' IDR' WHERE is_system;
+INSERT INTO public.accounts ("name", balance, currency, code, is_system) VALUES
+	('System FX Position IDR', 0, 'IDR', 'SYS_FX', true);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.reconciliation_discrepancies, public.reconciliation_runs;
+ALTER TABLE public.accounts DROP COLUMN IF EXISTS frozen;
@@ -0,0 +1,29 @@
+ALTER TABLE public.accounts ADD COLUMN frozen bool DEFAULT false NOT NULL;
+
+CREATE TABLE public.reconciliation_runs (
+	reconciliation_run_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	"trigger" varchar NOT NULL,
+	auto_freeze bool DEFAULT false NOT NULL,
+	accounts_checked int8 DEFAULT 0 NOT NULL,
+	mismatches int8 DEFAULT 0 NOT NULL,
+	started_at timestamptz NOT NULL,
+	finished_at timestamptz NULL,
+	CONSTRAINT reconciliation_runs_pk PRIMARY KEY (reconciliation_run_id)
+);
+
+
+CREATE TABLE public.reconciliation_discrepancies (
+	reconciliation_discrepancy_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	reconciliation_run_id int8 NOT NULL,
+	account_id int8 NOT NULL,
+	cached_balance int8 NOT NULL,
+	ledger_balance int8 NOT NULL,
+	difference int8 NOT NULL,
+	frozen bool DEFAULT false NOT NULL,
+	resolved_at timestamptz NULL,
+	resolution_note varchar NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT reconciliation_discrepancies_pk PRIMARY KEY (reconciliation_discrepancy_id),
+	CONSTRAINT reconciliation_discrepancies_run_fk FOREIGN KEY (reconciliation_run_id) REFERENCES public.reconciliation_runs(reconciliation_run_id),
+	CONSTRAINT reconciliation_discrepancies_account_fk FOREIGN KEY (account_id) REFERENCES public.accounts(account_id)
+);
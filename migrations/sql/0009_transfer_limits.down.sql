@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.transaction_from_account_date_idx;
+DROP TABLE IF EXISTS public.transfer_limits;
+ALTER TABLE public.accounts DROP COLUMN IF EXISTS tier;
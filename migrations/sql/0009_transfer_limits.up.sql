@@ -0,0 +1,22 @@
+ALTER TABLE public.accounts ADD COLUMN tier varchar DEFAULT 'basic' NOT NULL;
+
+CREATE TABLE public.transfer_limits (
+	transfer_limit_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	tier varchar NULL,
+	account_id int8 NULL,
+	per_transaction int8 NULL,
+	daily int8 NULL,
+	monthly int8 NULL,
+	CONSTRAINT transfer_limits_pk PRIMARY KEY (transfer_limit_id),
+	CONSTRAINT transfer_limits_account_fk FOREIGN KEY (account_id) REFERENCES public.accounts(account_id),
+	CONSTRAINT transfer_limits_owner_check CHECK ((tier IS NULL) <> (account_id IS NULL))
+);
+
+CREATE UNIQUE INDEX transfer_limits_tier_unique ON public.transfer_limits (tier) WHERE tier IS NOT NULL;
+CREATE UNIQUE INDEX transfer_limits_account_unique ON public.transfer_limits (account_id) WHERE account_id IS NOT NULL;
+CREATE INDEX transaction_from_account_date_idx ON public."transaction" (from_account_id, transaction_date);
+
+-- Default limit per tier (minor unit mata uang akun)
+INSERT INTO public.transfer_limits (tier, per_transaction, daily, monthly) VALUES
+	('basic', 1000000000, 2500000000, 10000000000),
+	('premium', 5000000000, 10000000000, 50000000000);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.revoked_tokens, public.refresh_tokens;
+ALTER TABLE public.auths DROP COLUMN IF EXISTS token_version;
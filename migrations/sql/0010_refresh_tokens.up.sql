@@ -0,0 +1,25 @@
+-- token_version dinaikkan untuk mencabut semua access token milik user
+ALTER TABLE public.auths ADD COLUMN token_version int8 DEFAULT 0 NOT NULL;
+
+CREATE TABLE public.refresh_tokens (
+	refresh_token_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	auth_id int8 NOT NULL,
+	family_id varchar NOT NULL,
+	token_hash varchar NOT NULL,
+	expires_at timestamptz NOT NULL,
+	revoked_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT refresh_tokens_pk PRIMARY KEY (refresh_token_id),
+	CONSTRAINT refresh_tokens_hash_unique UNIQUE (token_hash),
+	CONSTRAINT refresh_tokens_auth_fk FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id)
+);
+
+CREATE INDEX refresh_tokens_family_idx ON public.refresh_tokens (family_id);
+
+
+CREATE TABLE public.revoked_tokens (
+	jti varchar NOT NULL,
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT revoked_tokens_pk PRIMARY KEY (jti)
+);
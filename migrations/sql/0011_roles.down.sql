@@ -0,0 +1,3 @@
+ALTER TABLE public.auths
+	DROP CONSTRAINT IF EXISTS auths_role_check,
+	DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,6 @@
+ALTER TABLE public.auths
+	ADD COLUMN "role" varchar DEFAULT 'customer' NOT NULL,
+	ADD CONSTRAINT auths_role_check CHECK ("role" IN ('customer', 'support', 'admin'));
+
+-- Promosikan user menjadi staff secara manual, contoh:
+-- UPDATE public.auths SET "role" = 'admin', token_version = token_version + 1 WHERE username = 'admin';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.backup_codes;
+ALTER TABLE public.auths
+	DROP COLUMN IF EXISTS totp_last_step,
+	DROP COLUMN IF EXISTS totp_enabled,
+	DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,15 @@
+ALTER TABLE public.auths
+	ADD COLUMN totp_secret varchar NULL,
+	ADD COLUMN totp_enabled bool DEFAULT false NOT NULL,
+	ADD COLUMN totp_last_step int8 DEFAULT 0 NOT NULL;
+
+CREATE TABLE public.backup_codes (
+	backup_code_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	auth_id int8 NOT NULL,
+	code_hash varchar NOT NULL,
+	used_at timestamptz NULL,
+	CONSTRAINT backup_codes_pk PRIMARY KEY (backup_code_id),
+	CONSTRAINT backup_codes_auth_fk FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id)
+);
+
+CREATE INDEX backup_codes_auth_idx ON public.backup_codes (auth_id);
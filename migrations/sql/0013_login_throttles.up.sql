@@ -0,0 +1,25 @@
+CREATE TABLE public.login_throttles (
+	throttle_key varchar NOT NULL,
+	failures int4 DEFAULT 0 NOT NULL,
+	lockouts int4 DEFAULT 0 NOT NULL,
+	last_failed_at timestamptz NOT NULL,
+	locked_until timestamptz NULL,
+	CONSTRAINT login_throttles_pk PRIMARY KEY (throttle_key)
+);
+
+
+CREATE TABLE public.auth_events (
+	auth_event_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	auth_id int8 NULL,
+	"event" varchar NOT NULL,
+	username varchar NULL,
+	ip_address varchar NULL,
+	actor_id int8 NULL,
+	detail varchar NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT auth_events_pk PRIMARY KEY (auth_event_id),
+	CONSTRAINT auth_events_auth_fk FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id),
+	CONSTRAINT auth_events_actor_fk FOREIGN KEY (actor_id) REFERENCES public.auths(auth_id)
+);
+
+CREATE INDEX auth_events_username_idx ON public.auth_events (username);
@@ -0,0 +1,13 @@
+CREATE TABLE public.password_reset_tokens (
+	password_reset_token_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	auth_id int8 NOT NULL,
+	token_hash varchar NOT NULL,
+	expires_at timestamptz NOT NULL,
+	used_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT password_reset_tokens_pk PRIMARY KEY (password_reset_token_id),
+	CONSTRAINT password_reset_tokens_hash_unique UNIQUE (token_hash),
+	CONSTRAINT password_reset_tokens_auth_fk FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id)
+);
+
+CREATE INDEX password_reset_tokens_auth_idx ON public.password_reset_tokens (auth_id, created_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.api_keys, public.service_principals;
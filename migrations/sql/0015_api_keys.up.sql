@@ -0,0 +1,30 @@
+CREATE TABLE public.service_principals (
+	service_principal_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	"name" varchar NOT NULL,
+	description varchar DEFAULT '' NOT NULL,
+	created_by int8 NOT NULL,
+	disabled_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT service_principals_pk PRIMARY KEY (service_principal_id),
+	CONSTRAINT service_principals_created_by_fk FOREIGN KEY (created_by) REFERENCES public.auths(auth_id)
+);
+
+
+CREATE TABLE public.api_keys (
+	api_key_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	service_principal_id int8 NOT NULL,
+	"name" varchar NOT NULL,
+	prefix varchar NOT NULL,
+	key_hash varchar NOT NULL,
+	scopes varchar NOT NULL,
+	created_by int8 NOT NULL,
+	last_used_at timestamptz NULL,
+	last_used_ip varchar NULL,
+	expires_at timestamptz NULL,
+	revoked_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT api_keys_pk PRIMARY KEY (api_key_id),
+	CONSTRAINT api_keys_prefix_unique UNIQUE (prefix),
+	CONSTRAINT api_keys_principal_fk FOREIGN KEY (service_principal_id) REFERENCES public.service_principals(service_principal_id),
+	CONSTRAINT api_keys_created_by_fk FOREIGN KEY (created_by) REFERENCES public.auths(auth_id)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE public.sessions (
+	session_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	auth_id int8 NOT NULL,
+	family_id varchar NOT NULL,
+	device_name varchar NOT NULL,
+	user_agent varchar DEFAULT '' NOT NULL,
+	ip_address varchar DEFAULT '' NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	last_seen_at timestamptz DEFAULT now() NOT NULL,
+	revoked_at timestamptz NULL,
+	CONSTRAINT sessions_pk PRIMARY KEY (session_id),
+	CONSTRAINT sessions_family_unique UNIQUE (family_id),
+	CONSTRAINT sessions_auth_fk FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id)
+);
+
+CREATE INDEX sessions_auth_idx ON public.sessions (auth_id);
@@ -0,0 +1,18 @@
+-- Akun sistem yang dibuat oleh migrasi ini dibiarkan; aplikasi juga membuatnya otomatis
+UPDATE public.accounts a SET balance = a.balance - b.delta
+FROM (
+	SELECT l.account_id, SUM(l.credit - l.debit) AS delta
+	FROM public.journal_lines l
+	JOIN public.journal_entries e ON e.journal_entry_id = l.journal_entry_id
+	WHERE e.description = 'Opening balance (backfill)' AND e.transaction_id IS NULL
+	GROUP BY l.account_id
+) b
+WHERE a.account_id = b.account_id AND a.is_system;
+
+DELETE FROM public.journal_lines l
+USING public.journal_entries e
+WHERE e.journal_entry_id = l.journal_entry_id
+	AND e.description = 'Opening balance (backfill)' AND e.transaction_id IS NULL;
+
+DELETE FROM public.journal_entries
+WHERE description = 'Opening balance (backfill)' AND transaction_id IS NULL;
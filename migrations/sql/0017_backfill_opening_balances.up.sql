@@ -0,0 +1,50 @@
+-- Saldo akun yang dibuat sebelum jurnal ada tidak punya journal line, sehingga
+-- statement, rekonsiliasi dan jurnal per akun tidak cocok dengan accounts.balance.
+-- Selisih saldo dengan jumlah jurnal setiap akun nasabah dicatat sebagai satu entry
+-- saldo awal melawan SYS_TOPUP_FUNDING. Saldo nasabah tidak berubah; saldo akun
+-- sistem ikut digeser supaya tetap sama dengan jurnalnya.
+
+-- Akun sistem untuk mata uang selain IDR mungkin belum pernah dibuat aplikasi
+INSERT INTO public.accounts ("name", balance, currency, code, is_system)
+SELECT DISTINCT 'System Top-Up Funding ' || a.currency, 0, a.currency, 'SYS_TOPUP_FUNDING', true
+FROM public.accounts a
+WHERE NOT a.is_system
+ON CONFLICT (code, currency) DO NOTHING;
+
+DO $$
+DECLARE
+	r record;
+	entry_id int8;
+	system_id int8;
+BEGIN
+	FOR r IN
+		SELECT a.account_id, a.currency,
+			a.balance - COALESCE((SELECT SUM(l.credit - l.debit) FROM public.journal_lines l WHERE l.account_id = a.account_id), 0) AS diff,
+			-- Entry diberi tanggal aktivitas pertama akun supaya masuk saldo awal statement lama
+			COALESCE(LEAST(
+				(SELECT MIN(t.transaction_date) FROM public."transaction" t
+					WHERE a.account_id IN (t.account_id, t.from_account_id, t.to_account_id)),
+				(SELECT MIN(e.created_at) FROM public.journal_entries e
+					JOIN public.journal_lines l ON l.journal_entry_id = e.journal_entry_id
+					WHERE l.account_id = a.account_id)
+			), now()) AS created_at
+		FROM public.accounts a
+		WHERE NOT a.is_system
+		ORDER BY a.account_id
+	LOOP
+		CONTINUE WHEN r.diff = 0;
+
+		SELECT account_id INTO STRICT system_id FROM public.accounts
+		WHERE code = 'SYS_TOPUP_FUNDING' AND currency = r.currency AND is_system;
+
+		INSERT INTO public.journal_entries (description, created_at)
+		VALUES ('Opening balance (backfill)', r.created_at)
+		RETURNING journal_entry_id INTO entry_id;
+
+		INSERT INTO public.journal_lines (journal_entry_id, account_id, currency, debit, credit) VALUES
+			(entry_id, r.account_id, r.currency, GREATEST(-r.diff, 0), GREATEST(r.diff, 0)),
+			(entry_id, system_id, r.currency, GREATEST(r.diff, 0), GREATEST(-r.diff, 0));
+
+		UPDATE public.accounts SET balance = balance - r.diff WHERE account_id = system_id;
+	END LOOP;
+END $$;
@@ -0,0 +1,2 @@
+-- Header response asli (misal Location, Retry-After) ikut diputar ulang
+ALTER TABLE public.idempotency_keys ADD COLUMN response_headers jsonb NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.service_principal_accounts;
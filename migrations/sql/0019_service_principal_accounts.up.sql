@@ -0,0 +1,12 @@
+-- Akun sumber yang boleh didebit oleh service principal. Principal tanpa baris di
+-- sini tidak bisa mentransfer dari akun mana pun.
+CREATE TABLE public.service_principal_accounts (
+	service_principal_id int8 NOT NULL,
+	account_id int8 NOT NULL,
+	created_by int8 NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT service_principal_accounts_pk PRIMARY KEY (service_principal_id, account_id),
+	CONSTRAINT service_principal_accounts_principal_fk FOREIGN KEY (service_principal_id) REFERENCES public.service_principals(service_principal_id),
+	CONSTRAINT service_principal_accounts_account_fk FOREIGN KEY (account_id) REFERENCES public.accounts(account_id),
+	CONSTRAINT service_principal_accounts_created_by_fk FOREIGN KEY (created_by) REFERENCES public.auths(auth_id)
+);
@@ -0,0 +1,9 @@
+-- Hanya default IDR yang bisa dipertahankan tanpa kolom currency
+DELETE FROM public.transfer_limits WHERE tier IS NOT NULL AND currency <> 'IDR';
+
+DROP INDEX public.transfer_limits_tier_unique;
+CREATE UNIQUE INDEX transfer_limits_tier_unique ON public.transfer_limits (tier) WHERE tier IS NOT NULL;
+
+ALTER TABLE public.transfer_limits
+	DROP CONSTRAINT transfer_limits_currency_check,
+	DROP COLUMN currency;
@@ -0,0 +1,12 @@
+-- Limit tier dinyatakan dalam minor unit mata uang tertentu, jadi default tier
+-- sekarang per (tier, currency). Baris lama dibuat untuk IDR. Override akun tetap
+-- tanpa currency karena selalu memakai mata uang akunnya.
+ALTER TABLE public.transfer_limits ADD COLUMN currency bpchar(3) NULL;
+
+UPDATE public.transfer_limits SET currency = 'IDR' WHERE tier IS NOT NULL;
+
+ALTER TABLE public.transfer_limits
+	ADD CONSTRAINT transfer_limits_currency_check CHECK ((tier IS NULL) = (currency IS NULL));
+
+DROP INDEX public.transfer_limits_tier_unique;
+CREATE UNIQUE INDEX transfer_limits_tier_unique ON public.transfer_limits (tier, currency) WHERE tier IS NOT NULL;
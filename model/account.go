@@ -1,7 +1,12 @@
 package model
 
 type Account struct {
-	AccountID int64  `json:"account_id" gorm:"primaryKey;autoIncrement;<-:false"`
-	Name      string `json:"name"`
-	Balance   int64  `json:"balance"`
+	AccountID int64   `json:"account_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Name      string  `json:"name"`
+	Balance   int64   `json:"balance"`        // Dalam minor unit sesuai Currency
+	Currency  string  `json:"currency"`       // Kode ISO 4217, contoh IDR, USD, SGD
+	Code      *string `json:"code,omitempty"` // Hanya terisi untuk akun sistem, contoh: SYS_TOPUP_FUNDING
+	IsSystem  bool    `json:"-"`
+	Tier      string  `json:"tier"`   // Menentukan default limit transfer, contoh basic, premium
+	Frozen    bool    `json:"frozen"` // Dibekukan oleh rekonsiliasi, tidak bisa bertransaksi
 }
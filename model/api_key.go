@@ -0,0 +1,66 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Role untuk request yang diautentikasi dengan API key
+const RoleService = "service"
+
+// Scope yang bisa diberikan ke API key
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeAccountsWrite  = "accounts:write"
+	ScopeTransfersWrite = "transfers:write"
+)
+
+// Scopes adalah daftar scope yang valid
+var Scopes = []string{ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersWrite}
+
+// ServicePrincipal adalah identitas client non-manusia (batch job, service lain)
+type ServicePrincipal struct {
+	ServicePrincipalID int64      `json:"service_principal_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	CreatedBy          int64      `json:"created_by"`
+	DisabledAt         *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ServicePrincipalAccount memberi izin service principal mendebit satu akun sumber
+type ServicePrincipalAccount struct {
+	ServicePrincipalID int64     `json:"service_principal_id" gorm:"primaryKey"`
+	AccountID          int64     `json:"account_id" gorm:"primaryKey"`
+	CreatedBy          int64     `json:"created_by"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// APIKey milik service principal. Key hanya ditampilkan sekali saat dibuat; yang
+// disimpan adalah prefix (untuk identifikasi) dan hash-nya.
+type APIKey struct {
+	APIKeyID           int64      `json:"api_key_id" gorm:"column:api_key_id;primaryKey;autoIncrement;<-:false"`
+	ServicePrincipalID int64      `json:"service_principal_id"`
+	Name               string     `json:"name"`
+	Prefix             string     `json:"prefix"`
+	KeyHash            string     `json:"-"`
+	Scopes             string     `json:"scopes"` // Dipisah koma, misal "accounts:read,transfers:write"
+	CreatedBy          int64      `json:"created_by"`
+	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP         *string    `json:"last_used_ip,omitempty" gorm:"column:last_used_ip"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList memecah Scopes menjadi slice
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
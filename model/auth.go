@@ -1,8 +1,22 @@
 package model
 
+// Role yang dikenal sistem
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+)
+
 type Auth struct {
 	AuthID    int64 `gorm:"primaryKey;autoIncrement;<-:false"`
 	AccountID int64
 	Username  string
 	Password  string
+	Role      string `gorm:"default:customer"`
+	// Dinaikkan saat password diganti; access token dengan versi lama ditolak
+	TokenVersion int64
+	// Secret TOTP terisi sejak enrol, tetapi 2FA baru aktif setelah diverifikasi
+	TOTPSecret   *string `gorm:"column:totp_secret"`
+	TOTPEnabled  bool    `gorm:"column:totp_enabled"`
+	TOTPLastStep int64   `gorm:"column:totp_last_step"`
 }
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// FxRate berarti 1 unit BaseCurrency = Rate unit QuoteCurrency (dalam satuan mayor).
+type FxRate struct {
+	FxRateID      int64     `json:"fx_rate_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          string    `json:"rate" gorm:"type:numeric(24,12)"`
+	EffectiveAt   time.Time `json:"effective_at"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
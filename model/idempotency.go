@@ -0,0 +1,21 @@
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey menyimpan fingerprint request dan response asli untuk header Idempotency-Key.
+// ResponseCode 0 berarti request dengan key tersebut masih diproses.
+type IdempotencyKey struct {
+	IdempotencyKeyID int64 `gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID        int64 // 0 untuk endpoint tanpa autentikasi
+	Key              string
+	Method           string
+	Path             string
+	RequestHash      string
+	ResponseCode     int
+	ResponseBody     []byte
+	ResponseHeaders  http.Header `gorm:"serializer:json"` // Hanya header yang diset handler
+	CreatedAt        time.Time   `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// JournalEntry adalah satu kejadian akuntansi (top-up, transfer, penyesuaian).
+// Setiap entry wajib memiliki minimal dua JournalLine dengan total debit == total kredit
+// untuk setiap mata uang.
+type JournalEntry struct {
+	JournalEntryID int64         `json:"journal_entry_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	TransactionID  *int64        `json:"transaction_id,omitempty"`
+	Description    string        `json:"description"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	Lines          []JournalLine `json:"lines,omitempty" gorm:"foreignKey:JournalEntryID"`
+}
+
+// JournalLine adalah satu kaki (leg) dari JournalEntry. Hanya salah satu dari
+// Debit atau Credit yang boleh terisi.
+type JournalLine struct {
+	JournalLineID  int64  `json:"journal_line_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	JournalEntryID int64  `json:"journal_entry_id"`
+	AccountID      int64  `json:"account_id"`
+	Currency       string `json:"currency"` // Diisi otomatis dari mata uang akun saat diposting
+	Debit          int64  `json:"debit"`
+	Credit         int64  `json:"credit"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// LoginThrottle menyimpan percobaan login gagal per kunci ("user:<username>" atau
+// "ip:<alamat>"). Disimpan di database agar berlaku untuk semua instance API.
+type LoginThrottle struct {
+	ThrottleKey  string     `json:"throttle_key" gorm:"primaryKey"`
+	Failures     int        `json:"failures"`
+	Lockouts     int        `json:"lockouts"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+}
+
+// Jenis event audit autentikasi
+const (
+	AuthEventLocked        = "login_locked"
+	AuthEventUnlocked      = "login_unlocked"
+	AuthEventPasswordReset = "password_reset"
+)
+
+// AuthEvent adalah catatan audit untuk kejadian keamanan login
+type AuthEvent struct {
+	AuthEventID int64     `json:"auth_event_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AuthID      *int64    `json:"auth_id,omitempty"`
+	Event       string    `json:"event"`
+	Username    string    `json:"username,omitempty"`
+	IPAddress   string    `json:"ip_address,omitempty" gorm:"column:ip_address"`
+	ActorID     *int64    `json:"actor_id,omitempty"` // auth_id admin yang melakukan aksi
+	Detail      string    `json:"detail,omitempty"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Pemicu rekonsiliasi
+const (
+	ReconciliationTriggerSchedule = "schedule"
+	ReconciliationTriggerAdmin    = "admin"
+)
+
+// ReconciliationRun adalah satu kali eksekusi job rekonsiliasi saldo
+type ReconciliationRun struct {
+	ReconciliationRunID int64                       `json:"reconciliation_run_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Trigger             string                      `json:"trigger"`
+	AutoFreeze          bool                        `json:"auto_freeze"`
+	AccountsChecked     int64                       `json:"accounts_checked"`
+	Mismatches          int64                       `json:"mismatches"`
+	StartedAt           time.Time                   `json:"started_at"`
+	FinishedAt          *time.Time                  `json:"finished_at,omitempty"`
+	Discrepancies       []ReconciliationDiscrepancy `json:"discrepancies,omitempty" gorm:"foreignKey:ReconciliationRunID"`
+}
+
+// ReconciliationDiscrepancy mencatat akun yang accounts.balance-nya tidak sama dengan hasil hitung ulang jurnal
+type ReconciliationDiscrepancy struct {
+	ReconciliationDiscrepancyID int64      `json:"reconciliation_discrepancy_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	ReconciliationRunID         int64      `json:"reconciliation_run_id"`
+	AccountID                   int64      `json:"account_id"`
+	CachedBalance               int64      `json:"cached_balance"`
+	LedgerBalance               int64      `json:"ledger_balance"`
+	Difference                  int64      `json:"difference"` // cached - ledger
+	Frozen                      bool       `json:"frozen"`
+	ResolvedAt                  *time.Time `json:"resolved_at,omitempty"`
+	ResolutionNote              string     `json:"resolution_note,omitempty"`
+	CreatedAt                   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
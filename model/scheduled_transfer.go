@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Frekuensi jadwal transfer
+const (
+	ScheduleOnce    = "once"
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+	ScheduleCron    = "cron"
+)
+
+// Status jadwal transfer
+const (
+	ScheduleActive    = "active"
+	SchedulePaused    = "paused"
+	ScheduleCompleted = "completed"
+	ScheduleFailed    = "failed"
+)
+
+type ScheduledTransfer struct {
+	ScheduledTransferID int64      `json:"scheduled_transfer_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID           int64      `json:"account_id"`
+	ToAccountID         int64      `json:"to_account_id"`
+	Amount              int64      `json:"amount"`
+	Frequency           string     `json:"frequency"`
+	CronExpr            string     `json:"cron_expr,omitempty"`
+	StartAt             time.Time  `json:"start_at"`
+	EndAt               *time.Time `json:"end_at,omitempty"`
+	NextRunAt           *time.Time `json:"next_run_at,omitempty"`
+	Status              string     `json:"status"`
+	MaxRetries          int        `json:"max_retries"`
+	RetryCount          int        `json:"retry_count"` // Percobaan ulang untuk kejadian yang sedang berjalan
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ScheduledTransferRun adalah riwayat setiap eksekusi jadwal, baik sukses maupun gagal.
+type ScheduledTransferRun struct {
+	ScheduledTransferRunID int64     `json:"scheduled_transfer_run_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	ScheduledTransferID    int64     `json:"scheduled_transfer_id"`
+	TransactionID          *int64    `json:"transaction_id,omitempty"`
+	Success                bool      `json:"success"`
+	Error                  string    `json:"error,omitempty"`
+	Attempt                int       `json:"attempt"`
+	ExecutedAt             time.Time `json:"executed_at" gorm:"autoCreateTime"`
+}
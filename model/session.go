@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Session adalah satu login di satu perangkat. Refresh token dalam FamilyID yang
+// sama milik session ini, dan access token membawa session_id di klaim "sid".
+type Session struct {
+	SessionID  int64      `json:"session_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AuthID     int64      `json:"-"`
+	FamilyID   string     `json:"-"`
+	DeviceName string     `json:"device_name"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address" gorm:"column:ip_address"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
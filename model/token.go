@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// RefreshToken disimpan dalam bentuk hash. Setiap refresh menghasilkan token baru
+// (rotasi); token dalam satu FamilyID berasal dari login yang sama.
+type RefreshToken struct {
+	RefreshTokenID int64 `gorm:"primaryKey;autoIncrement;<-:false"`
+	AuthID         int64
+	FamilyID       string
+	TokenHash      string
+	ExpiresAt      time.Time
+	RevokedAt      *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+// RevokedToken adalah daftar jti access token yang dicabut sebelum kadaluarsa (logout)
+type RevokedToken struct {
+	JTI       string `gorm:"column:jti;primaryKey"`
+	ExpiresAt time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// BackupCode adalah kode cadangan 2FA sekali pakai, disimpan dalam bentuk hash
+type BackupCode struct {
+	BackupCodeID int64 `gorm:"primaryKey;autoIncrement;<-:false"`
+	AuthID       int64
+	CodeHash     string
+	UsedAt       *time.Time
+}
+
+// PasswordResetToken adalah token reset password sekali pakai, disimpan dalam bentuk hash
+type PasswordResetToken struct {
+	PasswordResetTokenID int64 `gorm:"primaryKey;autoIncrement;<-:false"`
+	AuthID               int64
+	TokenHash            string
+	ExpiresAt            time.Time
+	UsedAt               *time.Time
+	CreatedAt            time.Time `gorm:"autoCreateTime"`
+}
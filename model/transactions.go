@@ -2,13 +2,27 @@ package model
 
 import "time"
 
+// Jenis transaksi yang dicatat oleh sistem
+const (
+	TransactionTypeTopUp    = "topup"
+	TransactionTypeTransfer = "transfer"
+	TransactionTypeReversal = "reversal"
+)
+
 type Transaction struct {
 	TransactionID         int64     `json:"transaction_id" gorm:"primaryKey;autoIncrement"`
 	TransactionCategoryID *int64    `json:"transaction_category_id"` // Optional category for bonus
 	AccountID             int64     `json:"account_id"`
 	FromAccountID         *int64    `json:"from_account_id,omitempty"`
 	ToAccountID           *int64    `json:"to_account_id,omitempty"`
-	Amount                int64     `json:"amount"`
+	Amount                int64     `json:"amount"`                     // Dalam minor unit sesuai Currency
+	Currency              string    `json:"currency"`                   // Mata uang akun pengirim
+	CounterAmount         *int64    `json:"counter_amount,omitempty"`   // Nominal yang diterima jika beda mata uang
+	CounterCurrency       *string   `json:"counter_currency,omitempty"` // Mata uang akun penerima
+	FxRate                *string   `json:"fx_rate,omitempty"`          // Snapshot kurs: 1 Currency = FxRate CounterCurrency
+	Type                  string    `json:"type,omitempty"`
+	ReversalOfID          *int64    `json:"reversal_of_id,omitempty"`  // Transaksi asli yang dibalik oleh transaksi ini
+	ReversedAmount        int64     `json:"reversed_amount,omitempty"` // Total yang sudah dibalik dari transaksi ini
 	TransactionDate       time.Time `json:"transaction_date" gorm:"autoCreateTime"`
 }
 
@@ -0,0 +1,15 @@
+package model
+
+// TransferLimit adalah batas transfer keluar. Baris dengan Tier terisi adalah default
+// untuk tier tersebut dalam Currency, baris dengan AccountID terisi adalah override
+// per akun (dalam mata uang akun). Nilai nil berarti tidak dibatasi (untuk tier) atau
+// mengikuti tier (untuk override).
+type TransferLimit struct {
+	TransferLimitID int64   `json:"transfer_limit_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Tier            *string `json:"tier,omitempty"`
+	Currency        *string `json:"currency,omitempty"`
+	AccountID       *int64  `json:"account_id,omitempty"`
+	PerTransaction  *int64  `json:"per_transaction"`
+	Daily           *int64  `json:"daily"`
+	Monthly         *int64  `json:"monthly"`
+}
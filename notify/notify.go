@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Message adalah notifikasi untuk satu user. Belum ada email/telepon di tabel auths,
+// jadi implementasi produksi memetakan AuthID/Username ke kanal pengiriman sendiri.
+type Message struct {
+	AuthID   int64
+	Username string
+	Subject  string
+	Body     string
+}
+
+// Notifier mengirim pesan ke user (email, SMS, dll)
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// writerNotifier menulis pesan ke io.Writer; hanya untuk development
+type writerNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter membuat Notifier yang menulis pesan ke w, misalnya os.Stdout
+func NewWriter(w io.Writer) Notifier {
+	return &writerNotifier{w: w}
+}
+
+func (n *writerNotifier) Send(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := fmt.Fprintf(n.w, "--- %s\nTo: %s (auth_id %d)\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.Username, msg.AuthID, msg.Subject, msg.Body)
+	return err
+}
+
+// fileNotifier menambahkan pesan ke file lokal; hanya untuk development
+type fileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile membuat Notifier yang menambahkan pesan ke file di path
+func NewFile(path string) Notifier {
+	return &fileNotifier{path: path}
+}
+
+func (n *fileNotifier) Send(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return (&writerNotifier{w: f}).Send(ctx, msg)
+}
@@ -0,0 +1,45 @@
+package password
+
+// Manager menggabungkan policy dan hasher. Hash baru selalu memakai hasher Preferred,
+// sedangkan hash lama dari algoritma lain yang dikenal tetap bisa diverifikasi.
+type Manager struct {
+	Policy    Policy
+	preferred Hasher
+	hashers   []Hasher
+}
+
+// NewManager membuat Manager; preferred dipakai untuk hash baru, legacy hanya untuk verifikasi
+func NewManager(policy Policy, preferred Hasher, legacy ...Hasher) *Manager {
+	return &Manager{
+		Policy:    policy,
+		preferred: preferred,
+		hashers:   append([]Hasher{preferred}, legacy...),
+	}
+}
+
+// Validate memeriksa password baru terhadap policy
+func (m *Manager) Validate(username, password string) error {
+	return m.Policy.Validate(username, password)
+}
+
+// Hash membuat hash dengan hasher yang dipilih
+func (m *Manager) Hash(password string) (string, error) {
+	return m.preferred.Hash(password)
+}
+
+// Verify memeriksa password dan memberi tahu apakah hash perlu diperbarui karena
+// memakai algoritma lain atau parameter yang lebih lemah dari konfigurasi saat ini.
+func (m *Manager) Verify(encoded, password string) (ok bool, rehash bool, err error) {
+	for _, hasher := range m.hashers {
+		if !hasher.Owns(encoded) {
+			continue
+		}
+
+		ok, err := hasher.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, hasher != m.preferred || m.preferred.Outdated(encoded), nil
+	}
+	return false, false, ErrUnknownHash
+}
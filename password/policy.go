@@ -0,0 +1,128 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:embed common.txt
+var commonList string
+
+// common berisi password umum yang selalu ditolak
+var common = func() map[string]struct{} {
+	set := map[string]struct{}{}
+	scanner := bufio.NewScanner(strings.NewReader(commonList))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}()
+
+// Policy adalah aturan password baru
+type Policy struct {
+	MinLength     int
+	MaxLength     int // Batas atas agar hashing tidak bisa dipakai untuk DoS
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPolicy dipakai jika tidak dikonfigurasi
+var DefaultPolicy = Policy{
+	MinLength:    8,
+	MaxLength:    128,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+}
+
+// PolicyError berisi semua aturan yang tidak terpenuhi
+type PolicyError struct {
+	Violations []string `json:"violations"`
+}
+
+func (e *PolicyError) Error() string {
+	return "password does not meet policy: " + strings.Join(e.Violations, "; ")
+}
+
+// Validate memeriksa password terhadap policy. username dipakai untuk menolak
+// password yang sama dengan username.
+func (p Policy) Validate(username, password string) error {
+	var violations []string
+
+	length := len([]rune(password))
+	if length < p.MinLength {
+		violations = append(violations, "must be at least "+strconv.Itoa(p.MinLength)+" characters")
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, "must be at most "+strconv.Itoa(p.MaxLength)+" characters")
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	if username != "" && strings.EqualFold(password, username) {
+		violations = append(violations, "must not be the same as username")
+	}
+	if _, ok := common[strings.ToLower(password)]; ok {
+		violations = append(violations, "is too common")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// ParseClasses mengisi Require* dari daftar "upper,lower,digit,symbol"
+func (p *Policy) ParseClasses(classes string) error {
+	p.RequireUpper, p.RequireLower, p.RequireDigit, p.RequireSymbol = false, false, false, false
+	for _, class := range strings.Split(classes, ",") {
+		switch strings.TrimSpace(strings.ToLower(class)) {
+		case "":
+		case "upper":
+			p.RequireUpper = true
+		case "lower":
+			p.RequireLower = true
+		case "digit":
+			p.RequireDigit = true
+		case "symbol":
+			p.RequireSymbol = true
+		default:
+			return fmt.Errorf("unknown character class %q", class)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package reconcile
+
+import (
+	"context"
+	"log/slog"
+	"task-golang-batch2/health"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// accountCheck adalah hasil perbandingan saldo cache dengan saldo jurnal satu akun
+type accountCheck struct {
+	AccountID     int64
+	IsSystem      bool
+	CachedBalance int64
+	LedgerBalance int64
+}
+
+// Run menghitung ulang saldo setiap akun dari jurnal dan mencatat akun yang berbeda
+// dengan accounts.balance. Jika autoFreeze aktif, akun nasabah yang berbeda dibekukan
+// sampai ditinjau. Perbandingan dilakukan dalam satu query sehingga konsisten
+// walaupun ada transfer yang sedang berjalan.
+func Run(db *gorm.DB, trigger string, autoFreeze bool) (*model.ReconciliationRun, error) {
+	run := model.ReconciliationRun{
+		Trigger:    trigger,
+		AutoFreeze: autoFreeze,
+		StartedAt:  time.Now(),
+	}
+
+	// Run dibuat dan diselesaikan dalam transaksi yang sama sehingga query yang gagal
+	// tidak meninggalkan run tanpa finished_at
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+
+		var checks []accountCheck
+		err := tx.Raw(`
+			SELECT a.account_id, a.is_system, a.balance AS cached_balance,
+				COALESCE(SUM(l.credit - l.debit), 0) AS ledger_balance
+			FROM accounts a
+			LEFT JOIN journal_lines l ON l.account_id = a.account_id
+			GROUP BY a.account_id, a.is_system, a.balance`).
+			Scan(&checks).Error
+		if err != nil {
+			return err
+		}
+
+		for _, check := range checks {
+			if check.CachedBalance == check.LedgerBalance {
+				continue
+			}
+
+			discrepancy := model.ReconciliationDiscrepancy{
+				ReconciliationRunID: run.ReconciliationRunID,
+				AccountID:           check.AccountID,
+				CachedBalance:       check.CachedBalance,
+				LedgerBalance:       check.LedgerBalance,
+				Difference:          check.CachedBalance - check.LedgerBalance,
+				Frozen:              autoFreeze && !check.IsSystem,
+			}
+			if err := tx.Create(&discrepancy).Error; err != nil {
+				return err
+			}
+
+			if discrepancy.Frozen {
+				if err := tx.Model(&model.Account{}).Where("account_id = ?", check.AccountID).
+					Update("frozen", true).Error; err != nil {
+					return err
+				}
+			}
+			run.Mismatches++
+		}
+
+		finishedAt := time.Now()
+		run.AccountsChecked = int64(len(checks))
+		run.FinishedAt = &finishedAt
+		return tx.Model(&run).Select("accounts_checked", "mismatches", "finished_at").Updates(&run).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// Worker menjalankan rekonsiliasi secara berkala
+type Worker struct {
+	db         *gorm.DB
+	interval   time.Duration
+	autoFreeze bool
+	heartbeat  health.Heartbeat
+}
+
+func NewWorker(db *gorm.DB, interval time.Duration, autoFreeze bool) *Worker {
+	return &Worker{
+		db:         db,
+		interval:   interval,
+		autoFreeze: autoFreeze,
+	}
+}
+
+// Run menjalankan rekonsiliasi setiap interval sampai ctx dibatalkan. Rekonsiliasi
+// yang sedang berjalan diselesaikan dulu sebelum Run kembali.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.heartbeat.Start()
+	defer w.heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.heartbeat.Beat()
+
+		run, err := Run(w.db, model.ReconciliationTriggerSchedule, w.autoFreeze)
+		if err != nil {
+			slog.Error("reconcile run failed", "error", err)
+			continue
+		}
+		if run.Mismatches > 0 {
+			slog.Warn("reconcile found mismatched accounts", "reconciliation_run_id", run.ReconciliationRunID, "mismatches", run.Mismatches)
+		}
+	}
+}
+
+// Ready gagal jika loop Run tidak berjalan atau macet lebih dari dua interval
+func (w *Worker) Ready() health.Check {
+	return w.heartbeat.Check(2 * w.interval)
+}
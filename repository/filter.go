@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"errors"
+	"strconv"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TransactionFilter adalah filter yang dipakai bersama oleh /account/mutation,
+// /account/statement dan /transaction/list
+type TransactionFilter struct {
+	StartDate      *time.Time
+	EndDate        *time.Time
+	Direction      string // in atau out
+	MinAmount      *int64
+	MaxAmount      *int64
+	CategoryID     *int64
+	CounterpartyID *int64
+	Type           string
+	Sort           string
+}
+
+// Cursor menyimpan posisi baris terakhir (keyset) dan sort yang dipakai.
+// Klien hanya melihat string base64 yang opaque.
+type Cursor struct {
+	Sort  string `json:"s,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    int64  `json:"i"`
+}
+
+type transactionSort struct {
+	column string
+	desc   bool
+}
+
+const DefaultTransactionSort = "date_desc"
+
+var transactionSorts = map[string]transactionSort{
+	"date_desc":   {column: "transaction_date", desc: true},
+	"date_asc":    {column: "transaction_date", desc: false},
+	"amount_desc": {column: "amount", desc: true},
+	"amount_asc":  {column: "amount", desc: false},
+}
+
+// ValidSort memastikan nama sort dikenal
+func ValidSort(sort string) bool {
+	_, ok := transactionSorts[sort]
+	return ok
+}
+
+// AccountTransactions adalah query transaksi yang melibatkan akun sebagai pengirim
+// atau penerima, sudah difilter
+func AccountTransactions(db *gorm.DB, accountID int64, filter TransactionFilter) *gorm.DB {
+	query := db.Model(&model.Transaction{}).Where("from_account_id = ? OR to_account_id = ?", accountID, accountID)
+	return filter.Apply(query, accountID)
+}
+
+// Apply menambahkan kondisi filter ke query. accountID dipakai untuk direction dan counterparty.
+func (f TransactionFilter) Apply(query *gorm.DB, accountID int64) *gorm.DB {
+	if f.StartDate != nil {
+		query = query.Where("transaction_date >= ?", *f.StartDate)
+	}
+	if f.EndDate != nil {
+		query = query.Where("transaction_date <= ?", *f.EndDate)
+	}
+
+	switch f.Direction {
+	case "in":
+		query = query.Where("to_account_id = ?", accountID)
+	case "out":
+		query = query.Where("from_account_id = ?", accountID)
+	}
+
+	if f.MinAmount != nil {
+		query = query.Where("amount >= ?", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		query = query.Where("amount <= ?", *f.MaxAmount)
+	}
+	if f.CategoryID != nil {
+		query = query.Where("transaction_category_id = ?", *f.CategoryID)
+	}
+	if f.CounterpartyID != nil {
+		query = query.Where("(from_account_id = ? AND to_account_id = ?) OR (to_account_id = ? AND from_account_id = ?)",
+			accountID, *f.CounterpartyID, accountID, *f.CounterpartyID)
+	}
+	if f.Type != "" {
+		query = query.Where("type = ?", f.Type)
+	}
+
+	return query
+}
+
+// Paginate menerapkan urutan dan keyset cursor sesuai sort yang dipilih
+func (f TransactionFilter) Paginate(query *gorm.DB, cursor *Cursor) (*gorm.DB, error) {
+	sort := transactionSorts[f.Sort]
+	direction, comparator := "ASC", ">"
+	if sort.desc {
+		direction, comparator = "DESC", "<"
+	}
+
+	if cursor != nil {
+		value, err := f.cursorValue(cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("("+sort.column+", transaction_id) "+comparator+" (?, ?)", value, cursor.ID)
+	}
+
+	return query.Order(sort.column + " " + direction).Order("transaction_id " + direction), nil
+}
+
+// CursorFor membuat cursor yang menunjuk ke transaksi terakhir pada halaman
+func (f TransactionFilter) CursorFor(transaction model.Transaction) Cursor {
+	cursor := Cursor{Sort: f.Sort, ID: transaction.TransactionID}
+	switch transactionSorts[f.Sort].column {
+	case "transaction_date":
+		cursor.Value = transaction.TransactionDate.Format(time.RFC3339Nano)
+	case "amount":
+		cursor.Value = strconv.FormatInt(transaction.Amount, 10)
+	}
+	return cursor
+}
+
+// cursorValue membaca nilai kolom sort dari cursor; cursor dari sort lain ditolak
+func (f TransactionFilter) cursorValue(cursor *Cursor) (interface{}, error) {
+	if cursor.Sort != f.Sort {
+		return nil, ErrInvalidCursor
+	}
+
+	switch transactionSorts[f.Sort].column {
+	case "transaction_date":
+		parsed, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return parsed, nil
+	case "amount":
+		parsed, err := strconv.ParseInt(cursor.Value, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return parsed, nil
+	}
+	return nil, ErrInvalidCursor
+}
+
+// matches adalah padanan Apply untuk repository memory
+func (f TransactionFilter) matches(transaction model.Transaction, accountID int64) bool {
+	involves := func(id *int64, want int64) bool { return id != nil && *id == want }
+
+	if f.StartDate != nil && transaction.TransactionDate.Before(*f.StartDate) {
+		return false
+	}
+	if f.EndDate != nil && transaction.TransactionDate.After(*f.EndDate) {
+		return false
+	}
+
+	switch f.Direction {
+	case "in":
+		if !involves(transaction.ToAccountID, accountID) {
+			return false
+		}
+	case "out":
+		if !involves(transaction.FromAccountID, accountID) {
+			return false
+		}
+	}
+
+	if f.MinAmount != nil && transaction.Amount < *f.MinAmount {
+		return false
+	}
+	if f.MaxAmount != nil && transaction.Amount > *f.MaxAmount {
+		return false
+	}
+	if f.CategoryID != nil && !involves(transaction.TransactionCategoryID, *f.CategoryID) {
+		return false
+	}
+	if f.CounterpartyID != nil {
+		out := involves(transaction.FromAccountID, accountID) && involves(transaction.ToAccountID, *f.CounterpartyID)
+		in := involves(transaction.ToAccountID, accountID) && involves(transaction.FromAccountID, *f.CounterpartyID)
+		if !out && !in {
+			return false
+		}
+	}
+	if f.Type != "" && transaction.Type != f.Type {
+		return false
+	}
+	return true
+}
+
+// compare mengurutkan dua transaksi seperti ORDER BY dari Paginate: negatif jika a lebih dulu
+func (f TransactionFilter) compare(a, b model.Transaction) int {
+	sort := transactionSorts[f.Sort]
+
+	result := 0
+	switch sort.column {
+	case "transaction_date":
+		result = a.TransactionDate.Compare(b.TransactionDate)
+	case "amount":
+		result = compareInt64(a.Amount, b.Amount)
+	}
+	if result == 0 {
+		result = compareInt64(a.TransactionID, b.TransactionID)
+	}
+	if sort.desc {
+		result = -result
+	}
+	return result
+}
+
+// afterCursor adalah padanan keyset "(kolom, transaction_id) > / < (?, ?)" dari Paginate
+func (f TransactionFilter) afterCursor(transaction model.Transaction, cursor *Cursor, value interface{}) bool {
+	last := model.Transaction{TransactionID: cursor.ID}
+	switch v := value.(type) {
+	case time.Time:
+		last.TransactionDate = v
+	case int64:
+		last.Amount = v
+	}
+	return f.compare(last, transaction) < 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+	"math/big"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/ledger"
+	"task-golang-batch2/limits"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormStore meneruskan repository ke paket ledger, limits dan currency yang sudah ada
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGorm membuat Store di atas db. Jika db sudah berupa transaksi, Atomic
+// membuka savepoint di dalamnya.
+func NewGorm(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Accounts() AccountRepository         { return gormAccounts{s.db} }
+func (s *gormStore) Transactions() TransactionRepository { return gormTransactions{s.db} }
+func (s *gormStore) Journal() JournalRepository          { return gormJournal{s.db} }
+func (s *gormStore) Limits() LimitRepository             { return gormLimits{s.db} }
+func (s *gormStore) Rates() RateRepository               { return gormRates{s.db} }
+func (s *gormStore) Auths() AuthRepository               { return gormAuths{s.db} }
+
+func (s *gormStore) Atomic(fn func(Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// notFound menerjemahkan gorm.ErrRecordNotFound ke ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type gormAccounts struct{ db *gorm.DB }
+
+func (r gormAccounts) Get(accountID int64) (model.Account, error) {
+	var account model.Account
+	err := r.db.Where("account_id = ?", accountID).First(&account).Error
+	return account, notFound(err)
+}
+
+func (r gormAccounts) Create(account *model.Account) error {
+	return r.db.Create(account).Error
+}
+
+func (r gormAccounts) UpdateDetails(accountID int64, name, tier string) error {
+	updates := map[string]interface{}{"name": name}
+	if tier != "" {
+		updates["tier"] = tier
+	}
+	return r.db.Model(&model.Account{}).Where("account_id = ?", accountID).Updates(updates).Error
+}
+
+func (r gormAccounts) Lock(accountIDs ...int64) (map[int64]model.Account, error) {
+	return ledger.LockAccounts(r.db, accountIDs...)
+}
+
+func (r gormAccounts) PrincipalCanDebit(principalID, accountID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ServicePrincipalAccount{}).
+		Where("service_principal_id = ? AND account_id = ?", principalID, accountID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+type gormTransactions struct{ db *gorm.DB }
+
+func (r gormTransactions) Create(transaction *model.Transaction) error {
+	return r.db.Create(transaction).Error
+}
+
+func (r gormTransactions) Find(accountID int64, filter TransactionFilter, cursor *Cursor, limit int) ([]model.Transaction, error) {
+	query, err := filter.Paginate(AccountTransactions(r.db, accountID, filter), cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []model.Transaction
+	err = query.Limit(limit).Find(&transactions).Error
+	return transactions, err
+}
+
+func (r gormTransactions) Lock(transactionID int64) (model.Transaction, error) {
+	var transaction model.Transaction
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ?", transactionID).
+		First(&transaction).Error
+	return transaction, notFound(err)
+}
+
+func (r gormTransactions) AddReversed(transactionID, amount int64) error {
+	return r.db.Model(&model.Transaction{}).
+		Where("transaction_id = ?", transactionID).
+		Update("reversed_amount", gorm.Expr("reversed_amount + ?", amount)).Error
+}
+
+type gormJournal struct{ db *gorm.DB }
+
+func (r gormJournal) SystemAccountID(code, currency string) (int64, error) {
+	return ledger.SystemAccountID(r.db, code, currency)
+}
+
+func (r gormJournal) Post(entry *model.JournalEntry) error {
+	return ledger.Post(r.db, entry)
+}
+
+func (r gormJournal) HasEntry(transactionID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.JournalEntry{}).Where("transaction_id = ?", transactionID).Count(&count).Error
+	return count > 0, err
+}
+
+type gormLimits struct{ db *gorm.DB }
+
+func (r gormLimits) Policy(account model.Account) (limits.Policy, error) {
+	return limits.Resolve(r.db, account)
+}
+
+func (r gormLimits) Outgoing(accountID int64, since time.Time) ([]limits.Outgoing, error) {
+	return limits.OutgoingSince(r.db, accountID, since)
+}
+
+type gormRates struct{ db *gorm.DB }
+
+func (r gormRates) Latest(from, to string) (*big.Rat, string, error) {
+	return currency.LatestRate(r.db, from, to)
+}
+
+type gormAuths struct{ db *gorm.DB }
+
+func (r gormAuths) FindByUsername(username string) (model.Auth, error) {
+	var auth model.Auth
+	err := r.db.Where("username = ?", username).First(&auth).Error
+	return auth, notFound(err)
+}
+
+func (r gormAuths) UpdatePassword(authID int64, oldHash, newHash string) error {
+	return r.db.Model(&model.Auth{}).
+		Where("auth_id = ? AND password = ?", authID, oldHash).
+		Update("password", newHash).Error
+}
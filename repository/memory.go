@@ -0,0 +1,498 @@
+package repository
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/ledger"
+	"task-golang-batch2/limits"
+	"task-golang-batch2/model"
+	"time"
+)
+
+// Memory adalah Store di memori untuk menguji service tanpa Postgres. Atomic
+// memegang satu mutex untuk seluruh store (setara lock semua baris) dan
+// mengembalikan isi store ke snapshot jika fn gagal.
+type Memory struct {
+	mu   sync.Mutex
+	data memoryData
+}
+
+type memoryData struct {
+	nextID       int64
+	accounts     map[int64]model.Account
+	auths        map[int64]model.Auth
+	transactions []model.Transaction
+	entries      []model.JournalEntry
+	limits       []model.TransferLimit
+	rates        []model.FxRate
+	// principalAccounts berisi pasangan [service_principal_id, account_id] yang diizinkan
+	principalAccounts map[[2]int64]bool
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{data: memoryData{
+		accounts: map[int64]model.Account{},
+		auths:    map[int64]model.Auth{},
+
+		principalAccounts: map[[2]int64]bool{},
+	}}
+}
+
+func (d *memoryData) id() int64 {
+	d.nextID++
+	return d.nextID
+}
+
+func (d memoryData) clone() memoryData {
+	cloned := d
+	cloned.accounts = make(map[int64]model.Account, len(d.accounts))
+	for id, account := range d.accounts {
+		cloned.accounts[id] = account
+	}
+	cloned.auths = make(map[int64]model.Auth, len(d.auths))
+	for id, auth := range d.auths {
+		cloned.auths[id] = auth
+	}
+	cloned.transactions = append([]model.Transaction(nil), d.transactions...)
+	cloned.entries = append([]model.JournalEntry(nil), d.entries...)
+	cloned.limits = append([]model.TransferLimit(nil), d.limits...)
+	cloned.rates = append([]model.FxRate(nil), d.rates...)
+	cloned.principalAccounts = make(map[[2]int64]bool, len(d.principalAccounts))
+	for pair := range d.principalAccounts {
+		cloned.principalAccounts[pair] = true
+	}
+	return cloned
+}
+
+// AddAccount menyimpan akun apa adanya (termasuk saldo) dan mengisi AccountID jika kosong
+func (m *Memory) AddAccount(account model.Account) model.Account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if account.AccountID == 0 {
+		account.AccountID = m.data.id()
+	}
+	m.data.accounts[account.AccountID] = account
+	return account
+}
+
+// AddAuth menyimpan auth dan mengisi AuthID jika kosong
+func (m *Memory) AddAuth(auth model.Auth) model.Auth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if auth.AuthID == 0 {
+		auth.AuthID = m.data.id()
+	}
+	m.data.auths[auth.AuthID] = auth
+	return auth
+}
+
+// AddTransaction menyimpan transaksi lampau, misalnya untuk mengisi pemakaian limit
+func (m *Memory) AddTransaction(transaction model.Transaction) model.Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if transaction.TransactionID == 0 {
+		transaction.TransactionID = m.data.id()
+	}
+	m.data.transactions = append(m.data.transactions, transaction)
+	return transaction
+}
+
+func (m *Memory) AddLimit(limit model.TransferLimit) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.limits = append(m.data.limits, limit)
+}
+
+func (m *Memory) AddRate(rate model.FxRate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.rates = append(m.data.rates, rate)
+}
+
+// AllowPrincipal menambah akun ke allow-list sumber transfer service principal
+func (m *Memory) AllowPrincipal(principalID, accountID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.principalAccounts[[2]int64{principalID, accountID}] = true
+}
+
+// Entries mengembalikan semua journal entry yang sudah diposting
+func (m *Memory) Entries() []model.JournalEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.JournalEntry(nil), m.data.entries...)
+}
+
+func (m *Memory) Accounts() AccountRepository         { return memoryAccounts{m.store()} }
+func (m *Memory) Transactions() TransactionRepository { return memoryTransactions{m.store()} }
+func (m *Memory) Journal() JournalRepository          { return memoryJournal{m.store()} }
+func (m *Memory) Limits() LimitRepository             { return memoryLimits{m.store()} }
+func (m *Memory) Rates() RateRepository               { return memoryRates{m.store()} }
+func (m *Memory) Auths() AuthRepository               { return memoryAuths{m.store()} }
+
+func (m *Memory) Atomic(fn func(Store) error) error {
+	return m.store().Atomic(fn)
+}
+
+func (m *Memory) store() *memoryStore {
+	return &memoryStore{memory: m}
+}
+
+// memoryStore adalah tampilan Store atas Memory. Di dalam Atomic inTx bernilai
+// true dan mutex sudah dipegang, sehingga repository tidak mengunci lagi.
+type memoryStore struct {
+	memory *Memory
+	inTx   bool
+}
+
+func (s *memoryStore) Accounts() AccountRepository         { return memoryAccounts{s} }
+func (s *memoryStore) Transactions() TransactionRepository { return memoryTransactions{s} }
+func (s *memoryStore) Journal() JournalRepository          { return memoryJournal{s} }
+func (s *memoryStore) Limits() LimitRepository             { return memoryLimits{s} }
+func (s *memoryStore) Rates() RateRepository               { return memoryRates{s} }
+func (s *memoryStore) Auths() AuthRepository               { return memoryAuths{s} }
+
+// Atomic bersarang berlaku seperti savepoint: hanya perubahan fn dalam yang dibatalkan
+func (s *memoryStore) Atomic(fn func(Store) error) error {
+	if !s.inTx {
+		s.memory.mu.Lock()
+		defer s.memory.mu.Unlock()
+	}
+
+	snapshot := s.memory.data.clone()
+	if err := fn(&memoryStore{memory: s.memory, inTx: true}); err != nil {
+		s.memory.data = snapshot
+		return err
+	}
+	return nil
+}
+
+// with menjalankan fn dengan data store, mengunci mutex jika belum di dalam Atomic
+func (s *memoryStore) with(fn func(d *memoryData) error) error {
+	if !s.inTx {
+		s.memory.mu.Lock()
+		defer s.memory.mu.Unlock()
+	}
+	return fn(&s.memory.data)
+}
+
+type memoryAccounts struct{ s *memoryStore }
+
+func (r memoryAccounts) Get(accountID int64) (model.Account, error) {
+	var account model.Account
+	err := r.s.with(func(d *memoryData) error {
+		found, ok := d.accounts[accountID]
+		if !ok {
+			return ErrNotFound
+		}
+		account = found
+		return nil
+	})
+	return account, err
+}
+
+func (r memoryAccounts) Create(account *model.Account) error {
+	return r.s.with(func(d *memoryData) error {
+		account.AccountID = d.id()
+		d.accounts[account.AccountID] = *account
+		return nil
+	})
+}
+
+func (r memoryAccounts) UpdateDetails(accountID int64, name, tier string) error {
+	return r.s.with(func(d *memoryData) error {
+		account, ok := d.accounts[accountID]
+		if !ok {
+			return ErrNotFound
+		}
+		account.Name = name
+		if tier != "" {
+			account.Tier = tier
+		}
+		d.accounts[accountID] = account
+		return nil
+	})
+}
+
+// Lock cukup membaca akun karena Atomic sudah memegang mutex seluruh store
+func (r memoryAccounts) Lock(accountIDs ...int64) (map[int64]model.Account, error) {
+	locked := map[int64]model.Account{}
+	err := r.s.with(func(d *memoryData) error {
+		for _, id := range accountIDs {
+			if account, ok := d.accounts[id]; ok {
+				locked[id] = account
+			}
+		}
+		return nil
+	})
+	return locked, err
+}
+
+func (r memoryAccounts) PrincipalCanDebit(principalID, accountID int64) (bool, error) {
+	var allowed bool
+	err := r.s.with(func(d *memoryData) error {
+		allowed = d.principalAccounts[[2]int64{principalID, accountID}]
+		return nil
+	})
+	return allowed, err
+}
+
+type memoryTransactions struct{ s *memoryStore }
+
+func (r memoryTransactions) Create(transaction *model.Transaction) error {
+	return r.s.with(func(d *memoryData) error {
+		transaction.TransactionID = d.id()
+		if transaction.TransactionDate.IsZero() {
+			transaction.TransactionDate = time.Now()
+		}
+		d.transactions = append(d.transactions, *transaction)
+		return nil
+	})
+}
+
+func (r memoryTransactions) Find(accountID int64, filter TransactionFilter, cursor *Cursor, limit int) ([]model.Transaction, error) {
+	var value interface{}
+	if cursor != nil {
+		var err error
+		if value, err = filter.cursorValue(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	var found []model.Transaction
+	err := r.s.with(func(d *memoryData) error {
+		for _, transaction := range d.transactions {
+			involved := (transaction.FromAccountID != nil && *transaction.FromAccountID == accountID) ||
+				(transaction.ToAccountID != nil && *transaction.ToAccountID == accountID)
+			if !involved || !filter.matches(transaction, accountID) {
+				continue
+			}
+			if cursor != nil && !filter.afterCursor(transaction, cursor, value) {
+				continue
+			}
+			found = append(found, transaction)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(found, func(i, j int) bool { return filter.compare(found[i], found[j]) < 0 })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (r memoryTransactions) Lock(transactionID int64) (model.Transaction, error) {
+	var transaction model.Transaction
+	err := r.s.with(func(d *memoryData) error {
+		for _, candidate := range d.transactions {
+			if candidate.TransactionID == transactionID {
+				transaction = candidate
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+	return transaction, err
+}
+
+func (r memoryTransactions) AddReversed(transactionID, amount int64) error {
+	return r.s.with(func(d *memoryData) error {
+		for i := range d.transactions {
+			if d.transactions[i].TransactionID == transactionID {
+				d.transactions[i].ReversedAmount += amount
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+type memoryJournal struct{ s *memoryStore }
+
+func (r memoryJournal) SystemAccountID(code, currency string) (int64, error) {
+	var id int64
+	err := r.s.with(func(d *memoryData) error {
+		for _, account := range d.accounts {
+			if account.IsSystem && account.Code != nil && *account.Code == code && account.Currency == currency {
+				id = account.AccountID
+				return nil
+			}
+		}
+
+		id = d.id()
+		d.accounts[id] = model.Account{
+			AccountID: id,
+			Name:      code + " " + currency,
+			Currency:  currency,
+			Code:      &code,
+			IsSystem:  true,
+		}
+		return nil
+	})
+	return id, err
+}
+
+func (r memoryJournal) Post(entry *model.JournalEntry) error {
+	return r.s.with(func(d *memoryData) error {
+		for i := range entry.Lines {
+			account, ok := d.accounts[entry.Lines[i].AccountID]
+			if !ok {
+				return ledger.ErrAccountNotFound
+			}
+			entry.Lines[i].Currency = account.Currency
+		}
+
+		if err := ledger.Validate(entry.Lines); err != nil {
+			return err
+		}
+
+		entry.JournalEntryID = d.id()
+		entry.CreatedAt = time.Now()
+		for i := range entry.Lines {
+			entry.Lines[i].JournalLineID = d.id()
+			entry.Lines[i].JournalEntryID = entry.JournalEntryID
+
+			account := d.accounts[entry.Lines[i].AccountID]
+			account.Balance += entry.Lines[i].Credit - entry.Lines[i].Debit
+			d.accounts[account.AccountID] = account
+		}
+
+		stored := *entry
+		stored.Lines = append([]model.JournalLine(nil), entry.Lines...)
+		d.entries = append(d.entries, stored)
+		return nil
+	})
+}
+
+func (r memoryJournal) HasEntry(transactionID int64) (bool, error) {
+	var found bool
+	err := r.s.with(func(d *memoryData) error {
+		for _, entry := range d.entries {
+			if entry.TransactionID != nil && *entry.TransactionID == transactionID {
+				found = true
+				break
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+type memoryLimits struct{ s *memoryStore }
+
+func (r memoryLimits) Policy(account model.Account) (limits.Policy, error) {
+	tier := account.Tier
+	if tier == "" {
+		tier = limits.DefaultTier
+	}
+
+	var rows []model.TransferLimit
+	err := r.s.with(func(d *memoryData) error {
+		for _, row := range d.limits {
+			if (row.Tier != nil && *row.Tier == tier) || (row.AccountID != nil && *row.AccountID == account.AccountID) {
+				rows = append(rows, row)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return limits.Policy{}, err
+	}
+	return limits.FromRows(rows, account.Currency)
+}
+
+func (r memoryLimits) Outgoing(accountID int64, since time.Time) ([]limits.Outgoing, error) {
+	var rows []limits.Outgoing
+	err := r.s.with(func(d *memoryData) error {
+		for _, transaction := range d.transactions {
+			if transaction.FromAccountID == nil || *transaction.FromAccountID != accountID || !transaction.TransactionDate.After(since) {
+				continue
+			}
+			if transaction.Type != model.TransactionTypeTransfer {
+				continue
+			}
+			rows = append(rows, limits.Outgoing{
+				Amount:          transaction.Amount - transaction.ReversedAmount,
+				TransactionDate: transaction.TransactionDate,
+			})
+		}
+		return nil
+	})
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].TransactionDate.Before(rows[j].TransactionDate) })
+	return rows, err
+}
+
+type memoryRates struct{ s *memoryStore }
+
+func (r memoryRates) Latest(from, to string) (*big.Rat, string, error) {
+	if from == to {
+		return big.NewRat(1, 1), "1", nil
+	}
+
+	var latest *model.FxRate
+	err := r.s.with(func(d *memoryData) error {
+		now := time.Now()
+		for i, rate := range d.rates {
+			pair := (rate.BaseCurrency == from && rate.QuoteCurrency == to) ||
+				(rate.BaseCurrency == to && rate.QuoteCurrency == from)
+			if !pair || rate.EffectiveAt.After(now) {
+				continue
+			}
+			if latest == nil || rate.EffectiveAt.After(latest.EffectiveAt) {
+				latest = &d.rates[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if latest == nil {
+		return nil, "", currency.ErrRateNotFound
+	}
+
+	rate, err := currency.ParseRate(latest.Rate)
+	if err != nil {
+		return nil, "", err
+	}
+	if latest.BaseCurrency != from {
+		rate.Inv(rate)
+	}
+	return rate, rate.FloatString(12), nil
+}
+
+type memoryAuths struct{ s *memoryStore }
+
+func (r memoryAuths) FindByUsername(username string) (model.Auth, error) {
+	var auth model.Auth
+	err := r.s.with(func(d *memoryData) error {
+		for _, candidate := range d.auths {
+			if candidate.Username == username {
+				auth = candidate
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+	return auth, err
+}
+
+func (r memoryAuths) UpdatePassword(authID int64, oldHash, newHash string) error {
+	return r.s.with(func(d *memoryData) error {
+		auth, ok := d.auths[authID]
+		if ok && auth.Password == oldHash {
+			auth.Password = newHash
+			d.auths[authID] = auth
+		}
+		return nil
+	})
+}
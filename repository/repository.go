@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+	"math/big"
+	"task-golang-batch2/limits"
+	"task-golang-batch2/model"
+	"time"
+)
+
+// ErrNotFound dikembalikan repository jika baris yang dicari tidak ada
+var ErrNotFound = errors.New("record not found")
+
+// Store adalah pintu masuk ke semua repository. Implementasi GORM dipakai di
+// aplikasi, implementasi memory dipakai untuk menguji service tanpa Postgres.
+type Store interface {
+	Accounts() AccountRepository
+	Transactions() TransactionRepository
+	Journal() JournalRepository
+	Limits() LimitRepository
+	Rates() RateRepository
+	Auths() AuthRepository
+
+	// Atomic menjalankan fn di dalam satu transaksi. Store yang diterima fn terikat
+	// ke transaksi tersebut; jika fn mengembalikan error semua perubahan dibatalkan.
+	Atomic(fn func(Store) error) error
+}
+
+type AccountRepository interface {
+	Get(accountID int64) (model.Account, error)
+	// Create menyimpan akun baru dan mengisi AccountID; saldo diisi lewat jurnal
+	Create(account *model.Account) error
+	// UpdateDetails mengganti nama dan tier akun; tier kosong berarti tidak diubah
+	UpdateDetails(accountID int64, name, tier string) error
+	// Lock mengunci akun untuk sisa transaksi dengan urutan account_id menaik.
+	// Akun yang tidak ditemukan tidak ada di map hasil.
+	Lock(accountIDs ...int64) (map[int64]model.Account, error)
+	// PrincipalCanDebit melaporkan apakah akun ada di allow-list sumber transfer principal
+	PrincipalCanDebit(principalID, accountID int64) (bool, error)
+}
+
+type TransactionRepository interface {
+	Create(transaction *model.Transaction) error
+	// Find mengambil maksimal limit transaksi yang melibatkan akun sebagai pengirim
+	// atau penerima, sesuai filter dan dimulai setelah cursor (boleh nil)
+	Find(accountID int64, filter TransactionFilter, cursor *Cursor, limit int) ([]model.Transaction, error)
+	// Lock mengunci transaksi untuk sisa transaksi database, ErrNotFound jika tidak ada
+	Lock(transactionID int64) (model.Transaction, error)
+	// AddReversed menambah reversed_amount transaksi yang sudah dikunci
+	AddReversed(transactionID, amount int64) error
+}
+
+type JournalRepository interface {
+	// SystemAccountID mengembalikan akun sistem untuk kode dan mata uang, dibuat jika belum ada
+	SystemAccountID(code, currency string) (int64, error)
+	// Post menyimpan entry dan memperbarui saldo akun; aturannya sama dengan ledger.Post
+	Post(entry *model.JournalEntry) error
+	// HasEntry melaporkan apakah transaksi sudah punya journal entry
+	HasEntry(transactionID int64) (bool, error)
+}
+
+type LimitRepository interface {
+	Policy(account model.Account) (limits.Policy, error)
+	Outgoing(accountID int64, since time.Time) ([]limits.Outgoing, error)
+}
+
+type RateRepository interface {
+	// Latest sama dengan currency.LatestRate: kurs dan snapshot desimalnya
+	Latest(from, to string) (*big.Rat, string, error)
+}
+
+type AuthRepository interface {
+	FindByUsername(username string) (model.Auth, error)
+	// UpdatePassword hanya menimpa jika hash masih sama dengan oldHash
+	UpdatePassword(authID int64, oldHash, newHash string) error
+}
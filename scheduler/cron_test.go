@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronUnrestrictedDayFields(t *testing.T) {
+	tests := []struct {
+		expr           string
+		domAny, dowAny bool
+	}{
+		{"0 9 * * *", true, true},
+		{"0 9 */2 * 1", true, false},
+		{"0 9 1-31 * 1", true, false},
+		{"0 9 15 * 0-6", false, true},
+		{"0 9 15 * 1-7", false, true},
+		{"0 9 15 * */2", false, true},
+		{"0 9 1-30 * 1-5", false, false},
+		{"0 9 15 * 1", false, false},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if cron.domAny != tt.domAny || cron.dowAny != tt.dowAny {
+			t.Errorf("ParseCron(%q): domAny = %v, dowAny = %v, want %v, %v", tt.expr, cron.domAny, cron.dowAny, tt.domAny, tt.dowAny)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Sabtu, 1 Juni 2024
+	from := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		// Tanggal dengan step tetap dianggap "*": hanya Senin bertanggal ganjil
+		{"0 9 */2 * 1", time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 9 */2 * 2", time.Date(2024, 6, 11, 9, 0, 0, 0, time.UTC)},
+		// Rentang penuh sama dengan "*", jadi hanya hari Senin yang cocok
+		{"0 9 1-31 * 1", time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 9 15 * 0-6", time.Date(2024, 6, 15, 9, 0, 0, 0, time.UTC)},
+		// Keduanya dibatasi: cukup salah satu yang cocok
+		{"0 9 15 * 1", time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 6, 1, 12, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		got, ok := cron.Next(from)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%v) = %v, %v, want %v", tt.expr, from, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"errors"
+	"task-golang-batch2/model"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries dipakai jika klien tidak menentukan max_retries
+	DefaultMaxRetries = 3
+	// Jeda percobaan ulang pertama, berlipat dua untuk setiap percobaan berikutnya
+	retryBaseDelay = 5 * time.Minute
+)
+
+var (
+	ErrInvalidFrequency = errors.New("frequency must be one of once, daily, weekly, monthly, cron")
+	ErrInvalidWindow    = errors.New("end_at must be after start_at")
+)
+
+// Validate memeriksa frekuensi, ekspresi cron dan rentang waktu jadwal.
+func Validate(s *model.ScheduledTransfer) error {
+	switch s.Frequency {
+	case model.ScheduleOnce, model.ScheduleDaily, model.ScheduleWeekly, model.ScheduleMonthly:
+	case model.ScheduleCron:
+		if _, err := ParseCron(s.CronExpr); err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidFrequency
+	}
+
+	if s.EndAt != nil && !s.EndAt.After(s.StartAt) {
+		return ErrInvalidWindow
+	}
+	return nil
+}
+
+// occurrence mengembalikan kejadian ke-n (mulai dari 0) untuk frekuensi tetap.
+// Jadwal bulanan memakai tanggal start_at dan dibatasi ke akhir bulan (31 -> 30/28).
+func occurrence(s *model.ScheduledTransfer, n int) time.Time {
+	start := s.StartAt
+	switch s.Frequency {
+	case model.ScheduleDaily:
+		return start.AddDate(0, 0, n)
+	case model.ScheduleWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case model.ScheduleMonthly:
+		firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(n), 1,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+		day := start.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return firstOfMonth.AddDate(0, 0, day-1)
+	}
+	return start
+}
+
+// NextOccurrence mengembalikan kejadian pertama jadwal yang jatuh setelah `after`.
+// false berarti jadwal tidak memiliki kejadian lagi (sekali jalan atau melewati end_at).
+func NextOccurrence(s *model.ScheduledTransfer, after time.Time) (time.Time, bool) {
+	var next time.Time
+
+	switch s.Frequency {
+	case model.ScheduleOnce:
+		if !s.StartAt.After(after) {
+			return time.Time{}, false
+		}
+		next = s.StartAt
+	case model.ScheduleCron:
+		cron, err := ParseCron(s.CronExpr)
+		if err != nil {
+			return time.Time{}, false
+		}
+		from := after
+		if s.StartAt.After(from) {
+			from = s.StartAt.Add(-time.Minute)
+		}
+		var ok bool
+		if next, ok = cron.Next(from); !ok {
+			return time.Time{}, false
+		}
+	default:
+		// Perkirakan indeks kejadian lalu maju sampai melewati `after`
+		n := 0
+		if after.After(s.StartAt) {
+			days := int(after.Sub(s.StartAt).Hours() / 24)
+			switch s.Frequency {
+			case model.ScheduleDaily:
+				n = days - 1
+			case model.ScheduleWeekly:
+				n = days/7 - 1
+			case model.ScheduleMonthly:
+				n = days/31 - 1
+			}
+			if n < 0 {
+				n = 0
+			}
+		}
+		for next = occurrence(s, n); !next.After(after); next = occurrence(s, n) {
+			n++
+		}
+	}
+
+	if s.EndAt != nil && next.After(*s.EndAt) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// RetryDelay mengembalikan jeda sebelum percobaan ulang ke-attempt (1, 2, 3, ...).
+func RetryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 10 {
+		attempt = 10
+	}
+	return retryBaseDelay << uint(attempt-1)
+}
@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"task-golang-batch2/health"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+	"task-golang-batch2/service"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Worker menjalankan jadwal transfer yang sudah jatuh tempo. Aman dijalankan di
+// beberapa instance sekaligus karena setiap jadwal diklaim dengan FOR UPDATE SKIP LOCKED.
+type Worker struct {
+	db        *gorm.DB
+	interval  time.Duration
+	batchSize int
+	heartbeat health.Heartbeat
+}
+
+func NewWorker(db *gorm.DB, interval time.Duration) *Worker {
+	return &Worker{
+		db:        db,
+		interval:  interval,
+		batchSize: 100,
+	}
+}
+
+// Run memproses jadwal setiap interval sampai ctx dibatalkan. Jadwal yang sedang
+// dieksekusi diselesaikan dulu sebelum Run kembali.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.heartbeat.Start()
+	defer w.heartbeat.Stop()
+
+	for {
+		if err := w.RunDue(time.Now()); err != nil {
+			slog.Error("scheduler run failed", "error", err)
+		}
+		w.heartbeat.Beat()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ready gagal jika loop Run tidak berjalan atau macet lebih dari dua interval
+func (w *Worker) Ready() health.Check {
+	return w.heartbeat.Check(2 * w.interval)
+}
+
+// RunDue mengeksekusi semua jadwal aktif dengan next_run_at <= now.
+func (w *Worker) RunDue(now time.Time) error {
+	var ids []int64
+	err := w.db.Model(&model.ScheduledTransfer{}).
+		Where("status = ? AND next_run_at <= ?", model.ScheduleActive, now).
+		Order("next_run_at").
+		Limit(w.batchSize).
+		Pluck("scheduled_transfer_id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := w.execute(id, now); err != nil {
+			slog.Error("scheduled transfer failed", "scheduled_transfer_id", id, "error", err)
+		}
+	}
+	return nil
+}
+
+func (w *Worker) execute(id int64, now time.Time) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		// Klaim jadwal; jika sedang diproses instance lain, lewati
+		var schedule model.ScheduledTransfer
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("scheduled_transfer_id = ? AND status = ? AND next_run_at <= ?", id, model.ScheduleActive, now).
+			First(&schedule).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		// Transfer dijalankan di savepoint supaya kegagalannya tetap bisa dicatat
+		transfers := service.NewAccountService(repository.NewGorm(tx), nil)
+		transaction, transferErr := transfers.Transfer(schedule.AccountID, schedule.ToAccountID, schedule.Amount)
+
+		run := model.ScheduledTransferRun{
+			ScheduledTransferID: schedule.ScheduledTransferID,
+			Success:             transferErr == nil,
+			Attempt:             schedule.RetryCount + 1,
+		}
+		if transaction != nil && transferErr == nil {
+			run.TransactionID = &transaction.TransactionID
+		}
+		if transferErr != nil {
+			run.Error = transferErr.Error()
+		}
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+
+		ApplyResult(&schedule, now, transferErr)
+		return tx.Model(&schedule).Select("next_run_at", "status", "retry_count", "last_run_at", "last_error").
+			Updates(&schedule).Error
+	})
+}
+
+// ApplyResult memperbarui status jadwal setelah satu eksekusi sesuai kebijakan retry:
+// kegagalan dicoba ulang dengan exponential backoff sampai max_retries, setelah itu
+// jadwal berulang lanjut ke kejadian berikutnya sedangkan jadwal sekali jalan ditandai gagal.
+func ApplyResult(s *model.ScheduledTransfer, now time.Time, runErr error) {
+	s.LastRunAt = &now
+
+	if runErr != nil {
+		s.LastError = runErr.Error()
+		s.RetryCount++
+		if s.RetryCount <= s.MaxRetries {
+			retryAt := now.Add(RetryDelay(s.RetryCount))
+			s.NextRunAt = &retryAt
+			return
+		}
+	} else {
+		s.LastError = ""
+	}
+
+	s.RetryCount = 0
+	next, ok := NextOccurrence(s, now)
+	if !ok {
+		s.NextRunAt = nil
+		if runErr != nil && s.Frequency == model.ScheduleOnce {
+			s.Status = model.ScheduleFailed
+		} else {
+			s.Status = model.ScheduleCompleted
+		}
+		return
+	}
+	s.NextRunAt = &next
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"errors"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/ledger"
+	"task-golang-batch2/limits"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+	"time"
+)
+
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrNegativeBalance = errors.New("balance must not be negative")
+	// ErrSourceNotAllowed berarti akun sumber tidak ada di allow-list service principal
+	ErrSourceNotAllowed = errors.New("service principal is not allowed to debit this account")
+)
+
+type AccountService interface {
+	Create(account model.Account) (*model.Account, error)
+	Update(accountID int64, name, tier string, balance int64) error
+	TopUp(accountID, amount int64) (*model.Transaction, error)
+	Transfer(fromAccountID, toAccountID, amount int64) (*model.Transaction, error)
+	TransferAsPrincipal(principalID, fromAccountID, toAccountID, amount int64) (*model.Transaction, error)
+	Reverse(transactionID, amount int64, reason string, ownerID *int64) (*model.Transaction, error)
+	Mutations(accountID int64, filter repository.TransactionFilter, cursor *repository.Cursor, limit int) (MutationPage, error)
+}
+
+type accountService struct {
+	store repository.Store
+	now   func() time.Time
+}
+
+// NewAccountService membuat service di atas store. now boleh nil (time.Now);
+// pengujian memakainya untuk mengatur jendela limit.
+func NewAccountService(store repository.Store, now func() time.Time) AccountService {
+	if now == nil {
+		now = time.Now
+	}
+	return &accountService{
+		store: store,
+		now:   now,
+	}
+}
+
+// Create membuat akun nasabah. account.Balance adalah saldo awal yang diposting
+// sebagai jurnal dari SYS_TOPUP_FUNDING, bukan ditulis langsung ke kolom balance.
+func (s *accountService) Create(account model.Account) (*model.Account, error) {
+	openingBalance := account.Balance
+	if openingBalance < 0 {
+		return nil, ErrNegativeBalance
+	}
+	account.Balance = 0
+	account.Code = nil
+	account.IsSystem = false
+	account.Frozen = false
+	if account.Tier == "" {
+		account.Tier = limits.DefaultTier
+	}
+
+	// Mata uang default IDR, harus kode ISO 4217 yang dikenal
+	if account.Currency == "" {
+		account.Currency = currency.Default
+	}
+	var err error
+	if account.Currency, err = currency.Normalize(account.Currency); err != nil {
+		return nil, err
+	}
+
+	err = s.store.Atomic(func(store repository.Store) error {
+		if err := store.Accounts().Create(&account); err != nil {
+			return err
+		}
+		if openingBalance == 0 {
+			return nil
+		}
+
+		fundingID, err := store.Journal().SystemAccountID(ledger.SystemTopUpFunding, account.Currency)
+		if err != nil {
+			return err
+		}
+		return store.Journal().Post(&model.JournalEntry{
+			Description: "Opening balance",
+			Lines: []model.JournalLine{
+				ledger.Debit(fundingID, openingBalance),
+				ledger.Credit(account.AccountID, openingBalance),
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account.Balance = openingBalance
+	return &account, nil
+}
+
+// Update mengganti nama dan tier akun. Selisih balance dengan saldo saat ini
+// dicatat sebagai jurnal penyesuaian terhadap SYS_ADJUSTMENT.
+func (s *accountService) Update(accountID int64, name, tier string, balance int64) error {
+	return s.store.Atomic(func(store repository.Store) error {
+		accounts, err := store.Accounts().Lock(accountID)
+		if err != nil {
+			return err
+		}
+		account, ok := accounts[accountID]
+		if !ok || account.IsSystem {
+			return ErrAccountNotFound
+		}
+
+		if err := store.Accounts().UpdateDetails(accountID, name, tier); err != nil {
+			return err
+		}
+
+		// Selisih dihitung dari baris yang sudah dikunci
+		delta := balance - account.Balance
+		if delta == 0 {
+			return nil
+		}
+
+		adjustmentID, err := store.Journal().SystemAccountID(ledger.SystemAdjustment, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		entry := model.JournalEntry{Description: "Balance adjustment"}
+		if delta > 0 {
+			entry.Lines = []model.JournalLine{
+				ledger.Debit(adjustmentID, delta),
+				ledger.Credit(accountID, delta),
+			}
+		} else {
+			entry.Lines = []model.JournalLine{
+				ledger.Debit(accountID, -delta),
+				ledger.Credit(adjustmentID, -delta),
+			}
+		}
+		return store.Journal().Post(&entry)
+	})
+}
+
+// TopUp menambah saldo akun nasabah dari akun sistem SYS_TOPUP_FUNDING
+func (s *accountService) TopUp(accountID, amount int64) (*model.Transaction, error) {
+	if amount <= 0 {
+		return nil, ledger.ErrInvalidAmount
+	}
+
+	var transaction *model.Transaction
+	err := s.store.Atomic(func(store repository.Store) error {
+		account, err := store.Accounts().Get(accountID)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && account.IsSystem) {
+			return ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Akun yang dibekukan rekonsiliasi tidak bisa menerima top-up
+		if account.Frozen {
+			return ledger.ErrAccountFrozen
+		}
+
+		transaction = &model.Transaction{
+			AccountID:       accountID,
+			ToAccountID:     &accountID,
+			Amount:          amount,
+			Currency:        account.Currency,
+			Type:            model.TransactionTypeTopUp,
+			TransactionDate: s.now(),
+		}
+		if err := store.Transactions().Create(transaction); err != nil {
+			return err
+		}
+
+		// Dana top-up berasal dari akun sistem, saldo akun diperbarui lewat jurnal
+		fundingID, err := store.Journal().SystemAccountID(ledger.SystemTopUpFunding, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		return store.Journal().Post(&model.JournalEntry{
+			TransactionID: &transaction.TransactionID,
+			Description:   "Top-up",
+			Lines: []model.JournalLine{
+				ledger.Debit(fundingID, amount),
+				ledger.Credit(accountID, amount),
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// Transfer memindahkan saldo antar akun nasabah di dalam satu transaksi. Jika store
+// sudah berupa transaksi (misalnya worker jadwal), transfer berjalan di savepoint.
+func (s *accountService) Transfer(fromAccountID, toAccountID, amount int64) (*model.Transaction, error) {
+	if amount <= 0 {
+		return nil, ledger.ErrInvalidAmount
+	}
+	if fromAccountID == toAccountID {
+		return nil, ledger.ErrSelfTransfer
+	}
+
+	var transaction *model.Transaction
+	err := s.store.Atomic(func(store repository.Store) error {
+		var err error
+		transaction, err = s.transfer(store, fromAccountID, toAccountID, amount)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// TransferAsPrincipal sama dengan Transfer untuk request API key; akun sumber harus
+// ada di allow-list principal, selain itu ErrSourceNotAllowed
+func (s *accountService) TransferAsPrincipal(principalID, fromAccountID, toAccountID, amount int64) (*model.Transaction, error) {
+	if amount <= 0 {
+		return nil, ledger.ErrInvalidAmount
+	}
+	if fromAccountID == toAccountID {
+		return nil, ledger.ErrSelfTransfer
+	}
+
+	var transaction *model.Transaction
+	err := s.store.Atomic(func(store repository.Store) error {
+		allowed, err := store.Accounts().PrincipalCanDebit(principalID, fromAccountID)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrSourceNotAllowed
+		}
+		transaction, err = s.transfer(store, fromAccountID, toAccountID, amount)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// transfer mengunci kedua akun, memvalidasi penerima, limit dan saldo, mencatat
+// model.Transaction lalu memposting jurnalnya
+func (s *accountService) transfer(store repository.Store, fromAccountID, toAccountID, amount int64) (*model.Transaction, error) {
+	accounts, err := store.Accounts().Lock(fromAccountID, toAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAccount, ok := accounts[fromAccountID]
+	if !ok || fromAccount.IsSystem {
+		return nil, ledger.ErrSenderNotFound
+	}
+	toAccount, ok := accounts[toAccountID]
+	if !ok || toAccount.IsSystem {
+		return nil, ledger.ErrRecipientNotFound
+	}
+
+	if fromAccount.Frozen || toAccount.Frozen {
+		return nil, ledger.ErrAccountFrozen
+	}
+
+	// Limit transfer keluar dihitung setelah akun pengirim dikunci
+	now := s.now()
+	if err := checkLimit(store, fromAccount, amount, now); err != nil {
+		return nil, err
+	}
+
+	// Saldo dicek dari baris yang sudah dikunci
+	if fromAccount.Balance < amount {
+		return nil, ledger.ErrInsufficientBalance
+	}
+
+	transaction := model.Transaction{
+		AccountID:       fromAccountID,
+		FromAccountID:   &fromAccountID,
+		ToAccountID:     &toAccountID,
+		Amount:          amount,
+		Currency:        fromAccount.Currency,
+		Type:            model.TransactionTypeTransfer,
+		TransactionDate: now,
+	}
+
+	lines := ledger.TransferLines(fromAccountID, toAccountID, amount, 0, 0)
+
+	// Beda mata uang: konversi dengan kurs terbaru dan simpan snapshot kursnya
+	if fromAccount.Currency != toAccount.Currency {
+		rate, rateSnapshot, err := store.Rates().Latest(fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			return nil, err
+		}
+		counterAmount, err := currency.Convert(amount, fromAccount.Currency, toAccount.Currency, rate)
+		if err != nil {
+			return nil, err
+		}
+		if counterAmount <= 0 {
+			return nil, ledger.ErrAmountTooSmall
+		}
+
+		fxFromID, err := store.Journal().SystemAccountID(ledger.SystemFX, fromAccount.Currency)
+		if err != nil {
+			return nil, err
+		}
+		fxToID, err := store.Journal().SystemAccountID(ledger.SystemFX, toAccount.Currency)
+		if err != nil {
+			return nil, err
+		}
+		lines = ledger.FXLines(fromAccountID, toAccountID, amount, counterAmount, fxFromID, fxToID)
+
+		transaction.CounterAmount = &counterAmount
+		transaction.CounterCurrency = &toAccount.Currency
+		transaction.FxRate = &rateSnapshot
+	}
+
+	if err := store.Transactions().Create(&transaction); err != nil {
+		return nil, err
+	}
+
+	// Posting jurnal: debit pengirim, kredit penerima
+	entry := model.JournalEntry{
+		TransactionID: &transaction.TransactionID,
+		Description:   "Transfer",
+		Lines:         lines,
+	}
+	if err := store.Journal().Post(&entry); err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// checkLimit memastikan transfer sebesar amount tidak melampaui limit akun; policy
+// dan pemakaian dibaca lewat store, aturannya di limits.Evaluate. Panggil setelah
+// baris akun dikunci agar transfer bersamaan tidak lolos bersama.
+func checkLimit(store repository.Store, account model.Account, amount int64, now time.Time) error {
+	policy, err := store.Limits().Policy(account)
+	if err != nil {
+		return err
+	}
+
+	var rows []limits.Outgoing
+	if policy.Daily != nil || policy.Monthly != nil {
+		rows, err = store.Limits().Outgoing(account.AccountID, limits.Since(now))
+		if err != nil {
+			return err
+		}
+	}
+	return limits.Evaluate(policy, rows, amount, now)
+}
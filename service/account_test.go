@@ -0,0 +1,324 @@
+package service
+
+import (
+	"errors"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/ledger"
+	"task-golang-batch2/limits"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T) (*repository.Memory, AccountService) {
+	t.Helper()
+	store := repository.NewMemory()
+	return store, NewAccountService(store, func() time.Time { return testNow })
+}
+
+// openAccount membuat akun dengan saldo awal lewat jurnal, seperti handler Create
+func openAccount(t *testing.T, accounts AccountService, account model.Account) model.Account {
+	t.Helper()
+	created, err := accounts.Create(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *created
+}
+
+func balance(t *testing.T, store *repository.Memory, accountID int64) int64 {
+	t.Helper()
+	account, err := store.Accounts().Get(accountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return account.Balance
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestTransfer(t *testing.T) {
+	store, accounts := newTestService(t)
+	from := openAccount(t, accounts, model.Account{Name: "from", Balance: 10000})
+	to := openAccount(t, accounts, model.Account{Name: "to", Balance: 500})
+
+	transaction, err := accounts.Transfer(from.AccountID, to.AccountID, 2500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.Type != model.TransactionTypeTransfer || transaction.Amount != 2500 || transaction.Currency != "IDR" {
+		t.Errorf("unexpected transaction %+v", transaction)
+	}
+	if got := balance(t, store, from.AccountID); got != 7500 {
+		t.Errorf("sender balance = %d, want 7500", got)
+	}
+	if got := balance(t, store, to.AccountID); got != 3000 {
+		t.Errorf("recipient balance = %d, want 3000", got)
+	}
+
+	entries := store.Entries()
+	last := entries[len(entries)-1]
+	if last.TransactionID == nil || *last.TransactionID != transaction.TransactionID || len(last.Lines) != 2 {
+		t.Errorf("unexpected journal entry %+v", last)
+	}
+}
+
+func TestTransferRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to model.Account
+		amount   int64
+		want     error
+	}{
+		{"invalid amount", model.Account{Balance: 100}, model.Account{}, 0, ledger.ErrInvalidAmount},
+		{"insufficient balance", model.Account{Balance: 100}, model.Account{}, 101, ledger.ErrInsufficientBalance},
+		{"frozen sender", model.Account{Balance: 100, Frozen: true}, model.Account{}, 50, ledger.ErrAccountFrozen},
+		{"frozen recipient", model.Account{Balance: 100}, model.Account{Frozen: true}, 50, ledger.ErrAccountFrozen},
+		{"system recipient", model.Account{Balance: 100}, model.Account{IsSystem: true}, 50, ledger.ErrRecipientNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, accounts := newTestService(t)
+			// Saldo langsung diisi karena akun beku tidak bisa menerima jurnal
+			tt.from.Currency, tt.to.Currency = "IDR", "IDR"
+			from := store.AddAccount(tt.from)
+			to := store.AddAccount(tt.to)
+
+			_, err := accounts.Transfer(from.AccountID, to.AccountID, tt.amount)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if got := balance(t, store, from.AccountID); got != tt.from.Balance {
+				t.Errorf("sender balance changed to %d", got)
+			}
+			if got := balance(t, store, to.AccountID); got != tt.to.Balance {
+				t.Errorf("recipient balance changed to %d", got)
+			}
+			if len(store.Entries()) != 0 {
+				t.Errorf("journal entry posted on failed transfer")
+			}
+		})
+	}
+}
+
+func TestTransferSelf(t *testing.T) {
+	_, accounts := newTestService(t)
+	from := openAccount(t, accounts, model.Account{Balance: 100})
+
+	if _, err := accounts.Transfer(from.AccountID, from.AccountID, 10); !errors.Is(err, ledger.ErrSelfTransfer) {
+		t.Fatalf("err = %v, want ErrSelfTransfer", err)
+	}
+}
+
+func TestTransferLimit(t *testing.T) {
+	store, accounts := newTestService(t)
+	basic, idr := limits.DefaultTier, "IDR"
+	store.AddLimit(model.TransferLimit{Tier: &basic, Currency: &idr, PerTransaction: int64Ptr(5000), Daily: int64Ptr(8000)})
+
+	from := openAccount(t, accounts, model.Account{Balance: 100000})
+	to := openAccount(t, accounts, model.Account{})
+
+	if _, err := accounts.Transfer(from.AccountID, to.AccountID, 6000); err == nil {
+		t.Fatal("per-transaction limit not enforced")
+	} else if limitErr, ok := limits.AsLimitError(err); !ok || limitErr.Limit != limits.LimitPerTransaction {
+		t.Fatalf("err = %v, want per_transaction limit", err)
+	}
+
+	if _, err := accounts.Transfer(from.AccountID, to.AccountID, 5000); err != nil {
+		t.Fatal(err)
+	}
+	_, err := accounts.Transfer(from.AccountID, to.AccountID, 4000)
+	limitErr, ok := limits.AsLimitError(err)
+	if !ok || limitErr.Limit != limits.LimitDaily || limitErr.Used != 5000 {
+		t.Fatalf("err = %v, want daily limit with 5000 used", err)
+	}
+	if limitErr.ResetsAt == nil || !limitErr.ResetsAt.Equal(testNow.Add(24*time.Hour)) {
+		t.Errorf("resets_at = %v, want %v", limitErr.ResetsAt, testNow.Add(24*time.Hour))
+	}
+
+	// Baris tanpa tipe (bukan dari service transfer) tidak memakan limit
+	store.AddTransaction(model.Transaction{FromAccountID: &from.AccountID, Amount: 3000, TransactionDate: testNow})
+	if _, err := accounts.Transfer(from.AccountID, to.AccountID, 3000); err != nil {
+		t.Fatalf("untyped row counted towards limit: %v", err)
+	}
+}
+
+func TestTransferLimitCurrency(t *testing.T) {
+	store, accounts := newTestService(t)
+	basic, idr, usd := limits.DefaultTier, "IDR", "USD"
+	store.AddLimit(model.TransferLimit{Tier: &basic, Currency: &idr, PerTransaction: int64Ptr(1000000)})
+	store.AddLimit(model.TransferLimit{Tier: &basic, Currency: &usd, PerTransaction: int64Ptr(5000)})
+
+	// Limit IDR tidak berlaku untuk akun USD: 60.00 USD melampaui limit USD 50.00
+	from := openAccount(t, accounts, model.Account{Currency: "USD", Balance: 100000})
+	to := openAccount(t, accounts, model.Account{Currency: "USD"})
+	if _, err := accounts.Transfer(from.AccountID, to.AccountID, 6000); err == nil {
+		t.Fatal("USD per-transaction limit not enforced")
+	} else if limitErr, ok := limits.AsLimitError(err); !ok || limitErr.Max != 5000 {
+		t.Fatalf("err = %v, want per_transaction limit of 5000", err)
+	}
+
+	// Tier yang punya limit, tetapi tidak untuk SGD, menolak transfer
+	sgdFrom := openAccount(t, accounts, model.Account{Currency: "SGD", Balance: 100000})
+	sgdTo := openAccount(t, accounts, model.Account{Currency: "SGD"})
+	if _, err := accounts.Transfer(sgdFrom.AccountID, sgdTo.AccountID, 100); !errors.Is(err, limits.ErrCurrencyNotConfigured) {
+		t.Fatalf("err = %v, want ErrCurrencyNotConfigured", err)
+	}
+}
+
+func TestTransferFX(t *testing.T) {
+	store, accounts := newTestService(t)
+	store.AddRate(model.FxRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15000", EffectiveAt: testNow.Add(-time.Hour)})
+
+	from := openAccount(t, accounts, model.Account{Currency: "USD", Balance: 1000}) // 10.00 USD
+	to := openAccount(t, accounts, model.Account{Currency: "IDR"})
+
+	transaction, err := accounts.Transfer(from.AccountID, to.AccountID, 250) // 2.50 USD
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.CounterAmount == nil || transaction.CounterCurrency == nil || transaction.FxRate == nil {
+		t.Fatalf("missing FX snapshot: %+v", transaction)
+	}
+	// 2.50 USD * 15000 = 37500 IDR dalam minor unit IDR
+	want := int64(37500)
+	for i := 0; i < currency.MinorUnits("IDR"); i++ {
+		want *= 10
+	}
+	if *transaction.CounterAmount != want || *transaction.CounterCurrency != "IDR" {
+		t.Errorf("counter = %d %s, want %d IDR", *transaction.CounterAmount, *transaction.CounterCurrency, want)
+	}
+	if got := balance(t, store, from.AccountID); got != 750 {
+		t.Errorf("sender balance = %d, want 750", got)
+	}
+	if got := balance(t, store, to.AccountID); got != want {
+		t.Errorf("recipient balance = %d, want %d", got, want)
+	}
+
+	// Setiap mata uang seimbang lewat akun SYS_FX
+	entries := store.Entries()
+	sums := map[string]int64{}
+	for _, line := range entries[len(entries)-1].Lines {
+		sums[line.Currency] += line.Credit - line.Debit
+	}
+	for code, sum := range sums {
+		if sum != 0 {
+			t.Errorf("%s legs sum to %d", code, sum)
+		}
+	}
+}
+
+func TestTransferFXWithoutRate(t *testing.T) {
+	_, accounts := newTestService(t)
+	from := openAccount(t, accounts, model.Account{Currency: "USD", Balance: 1000})
+	to := openAccount(t, accounts, model.Account{Currency: "SGD"})
+
+	if _, err := accounts.Transfer(from.AccountID, to.AccountID, 100); !errors.Is(err, currency.ErrRateNotFound) {
+		t.Fatalf("err = %v, want ErrRateNotFound", err)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	store, accounts := newTestService(t)
+	from := openAccount(t, accounts, model.Account{Balance: 1000})
+	to := openAccount(t, accounts, model.Account{})
+
+	transaction, err := accounts.Transfer(from.AccountID, to.AccountID, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pengirim bukan penerima dana, tidak boleh membalik
+	if _, err := accounts.Reverse(transaction.TransactionID, 0, "", &from.AccountID); !errors.Is(err, ledger.ErrTransactionNotFound) {
+		t.Fatalf("err = %v, want ErrTransactionNotFound", err)
+	}
+
+	if _, err := accounts.Reverse(transaction.TransactionID, 200, "partial", &to.AccountID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := accounts.Reverse(transaction.TransactionID, 500, "", nil); !errors.Is(err, ledger.ErrReversalExceeds) {
+		t.Fatalf("err = %v, want ErrReversalExceeds", err)
+	}
+	reversal, err := accounts.Reverse(transaction.TransactionID, 0, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reversal.Amount != 400 || reversal.Type != model.TransactionTypeReversal {
+		t.Errorf("unexpected reversal %+v", reversal)
+	}
+	if _, err := accounts.Reverse(transaction.TransactionID, 0, "", nil); !errors.Is(err, ledger.ErrNotReversible) {
+		t.Fatalf("err = %v, want ErrNotReversible", err)
+	}
+
+	if got := balance(t, store, from.AccountID); got != 1000 {
+		t.Errorf("sender balance = %d, want 1000", got)
+	}
+	if got := balance(t, store, to.AccountID); got != 0 {
+		t.Errorf("recipient balance = %d, want 0", got)
+	}
+}
+
+func TestReverseRequiresPostedTransaction(t *testing.T) {
+	store, accounts := newTestService(t)
+	from := openAccount(t, accounts, model.Account{Balance: 1000})
+	to := openAccount(t, accounts, model.Account{})
+
+	// Baris dari /transaction/create tidak punya tipe maupun journal entry
+	manual := store.AddTransaction(model.Transaction{FromAccountID: &from.AccountID, ToAccountID: &to.AccountID, Amount: 300})
+	// Tipe transfer tetapi tidak pernah diposting ke jurnal
+	unposted := store.AddTransaction(model.Transaction{Type: model.TransactionTypeTransfer, FromAccountID: &from.AccountID, ToAccountID: &to.AccountID, Amount: 300})
+
+	for _, transaction := range []model.Transaction{manual, unposted} {
+		if _, err := accounts.Reverse(transaction.TransactionID, 0, "", nil); !errors.Is(err, ledger.ErrNotReversible) {
+			t.Errorf("reverse %+v: err = %v, want ErrNotReversible", transaction, err)
+		}
+	}
+	if got := balance(t, store, from.AccountID); got != 1000 {
+		t.Errorf("sender balance = %d, want 1000", got)
+	}
+	if got := balance(t, store, to.AccountID); got != 0 {
+		t.Errorf("recipient balance = %d, want 0", got)
+	}
+}
+
+func TestCreateAndUpdate(t *testing.T) {
+	store, accounts := newTestService(t)
+
+	if _, err := accounts.Create(model.Account{Balance: -1}); !errors.Is(err, ErrNegativeBalance) {
+		t.Fatalf("err = %v, want ErrNegativeBalance", err)
+	}
+
+	account := openAccount(t, accounts, model.Account{Name: "old", Balance: 700, Frozen: true, IsSystem: true})
+	if account.Frozen || account.IsSystem || account.Tier != limits.DefaultTier || account.Currency != "IDR" {
+		t.Errorf("client controlled fields not reset: %+v", account)
+	}
+
+	if err := accounts.Update(account.AccountID, "new", "premium", 400); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := store.Accounts().Get(account.AccountID)
+	if updated.Name != "new" || updated.Tier != "premium" || updated.Balance != 400 {
+		t.Errorf("unexpected account after update %+v", updated)
+	}
+
+	// Saldo selalu sama dengan jumlah jurnal akun
+	var journal int64
+	for _, entry := range store.Entries() {
+		for _, line := range entry.Lines {
+			if line.AccountID == account.AccountID {
+				journal += line.Credit - line.Debit
+			}
+		}
+	}
+	if journal != 400 {
+		t.Errorf("journal balance = %d, want 400", journal)
+	}
+
+	if err := accounts.Update(999, "x", "", 0); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("err = %v, want ErrAccountNotFound", err)
+	}
+}
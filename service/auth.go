@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"task-golang-batch2/model"
+	"task-golang-batch2/password"
+	"task-golang-batch2/repository"
+)
+
+var ErrInvalidCredentials = errors.New("login not valid")
+
+type AuthService interface {
+	Login(username, plain string) (LoginResult, error)
+}
+
+// LoginResult adalah hasil pengecekan password. Pada ErrInvalidCredentials, Auth
+// tetap terisi jika username ada sehingga kegagalan bisa dicatat ke auth tersebut.
+type LoginResult struct {
+	Auth              *model.Auth
+	TwoFactorRequired bool
+}
+
+type authService struct {
+	store     repository.Store
+	passwords *password.Manager
+	// dummyHash diverifikasi untuk username yang tidak ada supaya waktu respon sama
+	// dengan username yang ada dan password salah
+	dummyHash string
+}
+
+func NewAuthService(store repository.Store, passwords *password.Manager) AuthService {
+	// Dibuat dengan hasher utama sehingga biayanya sama dengan hash user
+	dummyHash, err := passwords.Hash("dummy password for unknown usernames")
+	if err != nil {
+		slog.Error("failed to create dummy password hash", "error", err)
+	}
+	return &authService{
+		store:     store,
+		passwords: passwords,
+		dummyHash: dummyHash,
+	}
+}
+
+// Login memeriksa username dan password. Throttle dan penerbitan token tetap di
+// handler karena bergantung pada IP dan request.
+func (s *authService) Login(username, plain string) (LoginResult, error) {
+	auth, err := s.store.Auths().FindByUsername(username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.passwords.Verify(s.dummyHash, plain)
+			return LoginResult{}, ErrInvalidCredentials
+		}
+		return LoginResult{}, err
+	}
+
+	valid, rehash, err := s.passwords.Verify(auth.Password, plain)
+	if err != nil || !valid {
+		return LoginResult{Auth: &auth}, ErrInvalidCredentials
+	}
+
+	// Hash lama (bcrypt atau parameter lemah) diganti dengan hash baru selagi password diketahui
+	if rehash {
+		s.rehash(&auth, plain)
+	}
+
+	// Jika 2FA aktif, password saja belum cukup
+	return LoginResult{Auth: &auth, TwoFactorRequired: auth.TOTPEnabled}, nil
+}
+
+// rehash menyimpan ulang hash dengan algoritma/parameter saat ini. Hanya menimpa
+// jika hash belum diganti request lain, dan tidak memutus sesi yang ada.
+func (s *authService) rehash(auth *model.Auth, plain string) {
+	hashed, err := s.passwords.Hash(plain)
+	if err != nil {
+		slog.Error("failed to rehash password", "auth_id", auth.AuthID, "error", err)
+		return
+	}
+	if err := s.store.Auths().UpdatePassword(auth.AuthID, auth.Password, hashed); err != nil {
+		slog.Error("failed to store rehashed password", "auth_id", auth.AuthID, "error", err)
+		return
+	}
+	auth.Password = hashed
+}
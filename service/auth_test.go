@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"task-golang-batch2/model"
+	"task-golang-batch2/password"
+	"task-golang-batch2/repository"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2id memakai parameter kecil supaya test cepat
+var testArgon2id = password.Argon2id{Memory: 64, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func newTestAuthService(t *testing.T) (*repository.Memory, AuthService) {
+	t.Helper()
+	store := repository.NewMemory()
+	passwords := password.NewManager(password.DefaultPolicy, testArgon2id, password.Bcrypt{Cost: bcrypt.MinCost})
+	return store, NewAuthService(store, passwords)
+}
+
+func hash(t *testing.T, hasher password.Hasher, plain string) string {
+	t.Helper()
+	hashed, err := hasher.Hash(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hashed
+}
+
+func TestLogin(t *testing.T) {
+	store, auths := newTestAuthService(t)
+	auth := store.AddAuth(model.Auth{Username: "alice", Password: hash(t, testArgon2id, "Correct-horse-1")})
+
+	result, err := auths.Login("alice", "Correct-horse-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Auth == nil || result.Auth.AuthID != auth.AuthID || result.TwoFactorRequired {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestLoginInvalid(t *testing.T) {
+	store, auths := newTestAuthService(t)
+	auth := store.AddAuth(model.Auth{Username: "alice", Password: hash(t, testArgon2id, "Correct-horse-1")})
+
+	// Password salah tetap mengembalikan Auth agar kegagalan bisa dicatat
+	result, err := auths.Login("alice", "wrong")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if result.Auth == nil || result.Auth.AuthID != auth.AuthID {
+		t.Errorf("auth not returned on wrong password: %+v", result)
+	}
+
+	result, err = auths.Login("bob", "Correct-horse-1")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if result.Auth != nil {
+		t.Errorf("auth returned for unknown username: %+v", result)
+	}
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	store, auths := newTestAuthService(t)
+	store.AddAuth(model.Auth{Username: "alice", Password: hash(t, testArgon2id, "Correct-horse-1"), TOTPEnabled: true})
+
+	result, err := auths.Login("alice", "Correct-horse-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TwoFactorRequired {
+		t.Error("two-factor not required")
+	}
+}
+
+func TestLoginRehashesLegacyHash(t *testing.T) {
+	store, auths := newTestAuthService(t)
+	legacy := hash(t, password.Bcrypt{Cost: bcrypt.MinCost}, "Correct-horse-1")
+	store.AddAuth(model.Auth{Username: "alice", Password: legacy})
+
+	if _, err := auths.Login("alice", "Correct-horse-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := store.Auths().FindByUsername("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Password == legacy || !strings.HasPrefix(stored.Password, "$argon2id$") {
+		t.Errorf("password not rehashed: %s", stored.Password)
+	}
+	if _, err := auths.Login("alice", "Correct-horse-1"); err != nil {
+		t.Fatalf("login after rehash: %v", err)
+	}
+}
+
+// countingHasher menghitung berapa kali Verify dipanggil
+type countingHasher struct {
+	password.Argon2id
+	verifies *int
+}
+
+func (h countingHasher) Verify(encoded, plain string) (bool, error) {
+	*h.verifies++
+	return h.Argon2id.Verify(encoded, plain)
+}
+
+func TestLoginUnknownUserVerifiesDummyHash(t *testing.T) {
+	var verifies int
+	passwords := password.NewManager(password.DefaultPolicy, countingHasher{testArgon2id, &verifies})
+	auths := NewAuthService(repository.NewMemory(), passwords)
+
+	if _, err := auths.Login("bob", "Correct-horse-1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if verifies != 1 {
+		t.Errorf("verifies = %d, want 1 so unknown usernames cost the same as wrong passwords", verifies)
+	}
+}
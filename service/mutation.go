@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+)
+
+// Mutation adalah transaksi beserta arah dan nominal yang benar-benar
+// bergerak di akun pemilik mutasi
+type Mutation struct {
+	model.Transaction
+	Direction       string `json:"direction"` // in atau out
+	AccountAmount   int64  `json:"account_amount"`
+	AccountCurrency string `json:"account_currency"`
+	Formatted       string `json:"formatted"`
+}
+
+// MutationPage adalah satu halaman mutasi; Next nil berarti tidak ada halaman berikutnya
+type MutationPage struct {
+	Items []Mutation
+	Next  *repository.Cursor
+}
+
+func NewMutation(transaction model.Transaction, accountID int64, accountCurrency string) Mutation {
+	item := Mutation{
+		Transaction:     transaction,
+		Direction:       "in",
+		AccountAmount:   transaction.Amount,
+		AccountCurrency: accountCurrency,
+	}
+
+	if transaction.FromAccountID != nil && *transaction.FromAccountID == accountID {
+		item.Direction = "out"
+	} else if transaction.CounterAmount != nil {
+		// Transfer masuk beda mata uang, yang diterima adalah counter amount
+		item.AccountAmount = *transaction.CounterAmount
+	}
+
+	item.Formatted = currency.Format(item.AccountAmount, item.AccountCurrency)
+	return item
+}
+
+// Mutations mengambil satu halaman transaksi akun, ditampilkan dari sudut pandang
+// akun ini dalam mata uangnya sendiri. repository.ErrInvalidCursor dikembalikan
+// jika cursor tidak cocok dengan sort filter.
+func (s *accountService) Mutations(accountID int64, filter repository.TransactionFilter, cursor *repository.Cursor, limit int) (MutationPage, error) {
+	account, err := s.store.Accounts().Get(accountID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return MutationPage{}, ErrAccountNotFound
+		}
+		return MutationPage{}, err
+	}
+
+	// Ambil limit+1 untuk mengetahui apakah masih ada halaman berikutnya
+	transactions, err := s.store.Transactions().Find(accountID, filter, cursor, limit+1)
+	if err != nil {
+		return MutationPage{}, err
+	}
+
+	var page MutationPage
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		next := filter.CursorFor(transactions[limit-1])
+		page.Next = &next
+	}
+
+	page.Items = make([]Mutation, 0, len(transactions))
+	for _, transaction := range transactions {
+		page.Items = append(page.Items, NewMutation(transaction, accountID, account.Currency))
+	}
+	return page, nil
+}
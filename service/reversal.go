@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"task-golang-batch2/currency"
+	"task-golang-batch2/ledger"
+	"task-golang-batch2/model"
+	"task-golang-batch2/repository"
+)
+
+// Reverse membuat transaksi kompensasi (refund penuh atau sebagian) untuk transaksi asli.
+// amount 0 berarti membalik seluruh sisa yang belum dibalik. Jika ownerID diisi, hanya
+// transaksi yang dikreditkan ke akun tersebut yang boleh dibalik (nasabah); nil untuk
+// support/admin. Transaksi asli dikunci sehingga dua reversal bersamaan tidak bisa
+// melebihi jumlah asli.
+func (s *accountService) Reverse(transactionID, amount int64, reason string, ownerID *int64) (*model.Transaction, error) {
+	if amount < 0 {
+		return nil, ledger.ErrInvalidAmount
+	}
+
+	var reversal *model.Transaction
+	err := s.store.Atomic(func(store repository.Store) error {
+		original, err := store.Transactions().Lock(transactionID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ledger.ErrTransactionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		// Transaksi milik akun lain diperlakukan seperti tidak ada
+		if ownerID != nil && ledger.CreditedAccountID(&original) != *ownerID {
+			return ledger.ErrTransactionNotFound
+		}
+
+		reversal, err = s.reverse(store, original, amount, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+func (s *accountService) reverse(store repository.Store, original model.Transaction, amount int64, reason string) (*model.Transaction, error) {
+	// Hanya top-up dan transfer yang sudah diposting ke jurnal yang bisa dibalik.
+	// Baris tanpa tipe (misalnya dari /transaction/create) tidak pernah memindahkan saldo.
+	if original.Type != model.TransactionTypeTopUp && original.Type != model.TransactionTypeTransfer {
+		return nil, ledger.ErrNotReversible
+	}
+	posted, err := store.Journal().HasEntry(original.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !posted {
+		return nil, ledger.ErrNotReversible
+	}
+
+	remaining := original.Amount - original.ReversedAmount
+	if remaining <= 0 {
+		return nil, ledger.ErrNotReversible
+	}
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return nil, ledger.ErrReversalExceeds
+	}
+
+	// Dana dikembalikan dari penerima ke sumber asli. Untuk top-up sumbernya akun sistem.
+	creditedID := ledger.CreditedAccountID(&original)
+	var sourceID int64
+	if original.FromAccountID != nil {
+		sourceID = *original.FromAccountID
+	} else {
+		credited, err := store.Accounts().Get(creditedID)
+		if err != nil {
+			return nil, ledger.ErrSenderNotFound
+		}
+		sourceID, err = store.Journal().SystemAccountID(ledger.SystemTopUpFunding, credited.Currency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	accounts, err := store.Accounts().Lock(creditedID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	credited, ok := accounts[creditedID]
+	if !ok {
+		return nil, ledger.ErrSenderNotFound
+	}
+	source, ok := accounts[sourceID]
+	if !ok {
+		return nil, ledger.ErrRecipientNotFound
+	}
+
+	if credited.Frozen || source.Frozen {
+		return nil, ledger.ErrAccountFrozen
+	}
+
+	// amount dalam mata uang transaksi asli; konversi memakai snapshot kurs asli
+	creditedAmount := amount
+	var inverseRate string
+	if original.CounterAmount != nil && original.FxRate != nil {
+		rate, err := currency.ParseRate(*original.FxRate)
+		if err != nil {
+			return nil, err
+		}
+		creditedAmount, err = currency.Convert(amount, original.Currency, credited.Currency, rate)
+		if err != nil {
+			return nil, err
+		}
+		if creditedAmount <= 0 {
+			return nil, ledger.ErrAmountTooSmall
+		}
+		inverseRate = new(big.Rat).Inv(rate).FloatString(12)
+	}
+
+	if !credited.IsSystem && credited.Balance < creditedAmount {
+		return nil, ledger.ErrInsufficientBalance
+	}
+
+	reversal := model.Transaction{
+		TransactionCategoryID: original.TransactionCategoryID,
+		AccountID:             creditedID,
+		FromAccountID:         &creditedID,
+		ToAccountID:           &sourceID,
+		Amount:                creditedAmount,
+		Currency:              credited.Currency,
+		Type:                  model.TransactionTypeReversal,
+		ReversalOfID:          &original.TransactionID,
+		TransactionDate:       s.now(),
+	}
+
+	lines := []model.JournalLine{
+		ledger.Debit(creditedID, creditedAmount),
+		ledger.Credit(sourceID, amount),
+	}
+	if credited.Currency != source.Currency {
+		fxFromID, err := store.Journal().SystemAccountID(ledger.SystemFX, credited.Currency)
+		if err != nil {
+			return nil, err
+		}
+		fxToID, err := store.Journal().SystemAccountID(ledger.SystemFX, source.Currency)
+		if err != nil {
+			return nil, err
+		}
+		lines = ledger.FXLines(creditedID, sourceID, creditedAmount, amount, fxFromID, fxToID)
+
+		reversal.CounterAmount = &amount
+		reversal.CounterCurrency = &source.Currency
+		reversal.FxRate = &inverseRate
+	}
+
+	if err := store.Transactions().Create(&reversal); err != nil {
+		return nil, err
+	}
+	if err := store.Transactions().AddReversed(original.TransactionID, amount); err != nil {
+		return nil, err
+	}
+
+	description := "Reversal"
+	if reason != "" {
+		description = "Reversal: " + reason
+	}
+	entry := model.JournalEntry{
+		TransactionID: &reversal.TransactionID,
+		Description:   description,
+		Lines:         lines,
+	}
+	if err := store.Journal().Post(&entry); err != nil {
+		return nil, err
+	}
+
+	return &reversal, nil
+}
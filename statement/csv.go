@@ -0,0 +1,68 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"task-golang-batch2/currency"
+	"time"
+)
+
+type csvWriter struct {
+	w        *csv.Writer
+	currency string
+}
+
+// NewCSVWriter membuat Writer yang menghasilkan statement dalam format CSV
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteHeader(h Header) error {
+	c.currency = h.Currency
+
+	rows := [][]string{
+		{"Account ID", strconv.FormatInt(h.AccountID, 10)},
+		{"Name", h.Name},
+		{"Currency", h.Currency},
+		{"Period", h.From.Format("2006-01-02") + " - " + h.To.Format("2006-01-02")},
+		{"Generated At", h.GeneratedAt.Format(time.RFC3339)},
+		{},
+		{"Date", "Transaction ID", "Description", "Debit", "Credit", "Balance"},
+		{"", "", "Opening Balance", "", "", currency.Format(h.OpeningBalance, h.Currency)},
+	}
+	return c.w.WriteAll(rows)
+}
+
+func (c *csvWriter) WriteLine(l Line) error {
+	debit, credit := "", ""
+	if l.Amount < 0 {
+		debit = currency.Format(-l.Amount, c.currency)
+	} else {
+		credit = currency.Format(l.Amount, c.currency)
+	}
+
+	err := c.w.Write([]string{
+		l.Date.Format(time.RFC3339),
+		strconv.FormatInt(l.TransactionID, 10),
+		l.Description,
+		debit,
+		credit,
+		currency.Format(l.Balance, c.currency),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Flush per baris supaya data langsung terkirim ke klien
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close(closingBalance int64) error {
+	if err := c.w.Write([]string{"", "", "Closing Balance", "", "", currency.Format(closingBalance, c.currency)}); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
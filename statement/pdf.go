@@ -0,0 +1,188 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"task-golang-batch2/currency"
+	"time"
+)
+
+// Ukuran halaman A4 dalam point dan tata letak baris
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 8
+	pdfLineHeight   = 11
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// Nomor objek tetap; objek halaman dialokasikan setelahnya
+const (
+	pdfCatalogObj = 1
+	pdfPagesObj   = 2
+	pdfFontObj    = 3
+)
+
+// pdfWriter menulis PDF minimal (teks Courier) langsung ke io.Writer. Hanya isi satu
+// halaman yang ditampung di memori; offset objek dicatat untuk tabel xref di akhir.
+type pdfWriter struct {
+	w        io.Writer
+	written  int64
+	offsets  map[int]int64
+	nextObj  int
+	pageObjs []int
+	page     *bytes.Buffer
+	lines    int
+	currency string
+	err      error
+}
+
+// NewPDFWriter membuat Writer yang menghasilkan statement dalam format PDF
+func NewPDFWriter(w io.Writer) Writer {
+	return &pdfWriter{
+		w:       w,
+		offsets: map[int]int64{},
+		nextObj: pdfFontObj + 1,
+	}
+}
+
+func (p *pdfWriter) raw(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.written += int64(n)
+	p.err = err
+}
+
+func (p *pdfWriter) beginObj(num int) {
+	p.offsets[num] = p.written
+	p.raw("%d 0 obj\n", num)
+}
+
+func (p *pdfWriter) allocObj() int {
+	num := p.nextObj
+	p.nextObj++
+	return num
+}
+
+// text menambahkan satu baris teks ke halaman aktif, membuka halaman baru jika penuh
+func (p *pdfWriter) text(line string) {
+	if p.page == nil || p.lines >= pdfLinesPerPage {
+		p.flushPage()
+		p.page = &bytes.Buffer{}
+		p.lines = 0
+		fmt.Fprintf(p.page, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+	}
+	fmt.Fprintf(p.page, "(%s) Tj T*\n", pdfEscape(line))
+	p.lines++
+}
+
+// flushPage menulis content stream dan objek halaman yang sedang aktif
+func (p *pdfWriter) flushPage() {
+	if p.page == nil {
+		return
+	}
+	p.page.WriteString("ET\n")
+
+	contentObj := p.allocObj()
+	p.beginObj(contentObj)
+	p.raw("<< /Length %d >>\nstream\n", p.page.Len())
+	p.raw("%s", p.page.String())
+	p.raw("endstream\nendobj\n")
+
+	pageObj := p.allocObj()
+	p.beginObj(pageObj)
+	p.raw("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+		pdfPagesObj, pdfPageWidth, pdfPageHeight, pdfFontObj, contentObj)
+	p.pageObjs = append(p.pageObjs, pageObj)
+
+	p.page = nil
+}
+
+func (p *pdfWriter) WriteHeader(h Header) error {
+	p.currency = h.Currency
+
+	p.raw("%%PDF-1.4\n")
+	p.beginObj(pdfCatalogObj)
+	p.raw("<< /Type /Catalog /Pages %d 0 R >>\nendobj\n", pdfPagesObj)
+	p.beginObj(pdfFontObj)
+	p.raw("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>\nendobj\n")
+
+	p.text("ACCOUNT STATEMENT")
+	p.text("")
+	p.text(fmt.Sprintf("Account ID   : %d", h.AccountID))
+	p.text(fmt.Sprintf("Name         : %s", h.Name))
+	p.text(fmt.Sprintf("Currency     : %s", h.Currency))
+	p.text(fmt.Sprintf("Period       : %s - %s", h.From.Format("2006-01-02"), h.To.Format("2006-01-02")))
+	p.text(fmt.Sprintf("Generated At : %s", h.GeneratedAt.Format(time.RFC3339)))
+	p.text("")
+	p.text(pdfRow("Date", "Trx ID", "Description", "Debit", "Credit", "Balance"))
+	p.text(strings.Repeat("-", 100))
+	p.text(pdfRow("", "", "Opening Balance", "", "", currency.Format(h.OpeningBalance, h.Currency)))
+	return p.err
+}
+
+func (p *pdfWriter) WriteLine(l Line) error {
+	debit, credit := "", ""
+	if l.Amount < 0 {
+		debit = currency.Format(-l.Amount, p.currency)
+	} else {
+		credit = currency.Format(l.Amount, p.currency)
+	}
+
+	p.text(pdfRow(l.Date.Format("2006-01-02 15:04"), strconv.FormatInt(l.TransactionID, 10),
+		l.Description, debit, credit, currency.Format(l.Balance, p.currency)))
+	return p.err
+}
+
+func (p *pdfWriter) Close(closingBalance int64) error {
+	p.text(strings.Repeat("-", 100))
+	p.text(pdfRow("", "", "Closing Balance", "", "", currency.Format(closingBalance, p.currency)))
+	p.flushPage()
+
+	// Objek Pages ditulis terakhir karena daftar halamannya baru diketahui sekarang
+	kids := make([]string, 0, len(p.pageObjs))
+	for _, obj := range p.pageObjs {
+		kids = append(kids, fmt.Sprintf("%d 0 R", obj))
+	}
+	p.beginObj(pdfPagesObj)
+	p.raw("<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(p.pageObjs))
+
+	xref := p.written
+	p.raw("xref\n0 %d\n0000000000 65535 f \n", p.nextObj)
+	for num := 1; num < p.nextObj; num++ {
+		p.raw("%010d 00000 n \n", p.offsets[num])
+	}
+	p.raw("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", p.nextObj, pdfCatalogObj, xref)
+	return p.err
+}
+
+// pdfRow menyusun kolom dengan lebar tetap (font Courier monospace)
+func pdfRow(date, id, description, debit, credit, balance string) string {
+	if len(description) > 34 {
+		description = description[:31] + "..."
+	}
+	return fmt.Sprintf("%-16s %-8s %-34s %12s %12s %14s", date, id, description, debit, credit, balance)
+}
+
+// pdfEscape meng-escape karakter khusus string PDF dan mengganti karakter non-ASCII
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
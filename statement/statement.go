@@ -0,0 +1,34 @@
+package statement
+
+import (
+	"time"
+)
+
+// Header berisi detail akun dan periode yang dicetak di awal statement
+type Header struct {
+	AccountID      int64
+	Name           string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	GeneratedAt    time.Time
+}
+
+// Line adalah satu mutasi beserta saldo berjalan setelah mutasi tersebut.
+// Amount bertanda: positif untuk dana masuk, negatif untuk dana keluar.
+type Line struct {
+	Date          time.Time
+	TransactionID int64
+	Description   string
+	Amount        int64
+	Balance       int64
+}
+
+// Writer menulis statement secara streaming: header sekali, lalu setiap baris,
+// lalu Close dengan saldo akhir. Implementasi tidak menyimpan seluruh baris di memori.
+type Writer interface {
+	WriteHeader(Header) error
+	WriteLine(Line) error
+	Close(closingBalance int64) error
+}
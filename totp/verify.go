@@ -0,0 +1,80 @@
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"task-golang-batch2/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// backupCodeAlphabet tanpa karakter yang mudah tertukar (0/O, 1/I)
+const backupCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// BackupCodeCount adalah jumlah kode cadangan yang dibuat saat 2FA diaktifkan
+const BackupCodeCount = 10
+
+func hashBackupCode(code string) string {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateBackupCodes mengganti semua kode cadangan user dan mengembalikan kode
+// dalam bentuk teks (hanya ditampilkan sekali, yang disimpan hanya hash-nya).
+func GenerateBackupCodes(tx *gorm.DB, authID int64) ([]string, error) {
+	if err := tx.Where("auth_id = ?", authID).Delete(&model.BackupCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, BackupCodeCount)
+	records := make([]model.BackupCode, 0, BackupCodeCount)
+	for i := 0; i < BackupCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		for j := range b {
+			b[j] = backupCodeAlphabet[int(b[j])%len(backupCodeAlphabet)]
+		}
+		code := string(b[:5]) + "-" + string(b[5:])
+
+		codes = append(codes, code)
+		records = append(records, model.BackupCode{AuthID: authID, CodeHash: hashBackupCode(code)})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify memeriksa kode TOTP atau kode cadangan milik user. Kode TOTP yang sudah
+// dipakai (langkah waktu yang sama atau lebih lama) dan kode cadangan bekas ditolak.
+func Verify(tx *gorm.DB, auth *model.Auth, code string) (bool, error) {
+	if auth.TOTPSecret == nil || *auth.TOTPSecret == "" {
+		return false, nil
+	}
+
+	if step, ok := Validate(*auth.TOTPSecret, code, time.Now()); ok {
+		result := tx.Model(&model.Auth{}).
+			Where("auth_id = ? AND totp_last_step < ?", auth.AuthID, step).
+			Update("totp_last_step", step)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		return result.RowsAffected == 1, nil
+	}
+
+	// Bukan kode TOTP, coba sebagai kode cadangan sekali pakai
+	result := tx.Model(&model.BackupCode{}).
+		Where("auth_id = ? AND code_hash = ? AND used_at IS NULL", auth.AuthID, hashBackupCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}